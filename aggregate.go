@@ -0,0 +1,267 @@
+package positional_line
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync/atomic"
+)
+
+// aggregated is set once a record struct with totals is compiled; until
+// then records are not added to any totals
+var aggregated atomic.Bool
+
+// AggregateKind is the total computed for a trailer field
+type AggregateKind int
+
+const (
+	// AggregateNone is a field that holds its own value
+	AggregateNone AggregateKind = iota
+	// AggregateCount counts records
+	AggregateCount
+	// AggregateSum adds up a numeric field of a record struct
+	AggregateSum
+)
+
+// Aggregate is set on trailer fields by the count, count=Record,
+// sum=Record.Field and scope=file|batch modifiers. The Encoder fills them
+// from the records written so far, the trailer included, and the Decoder
+// checks them against the records read.
+type Aggregate struct {
+	Kind AggregateKind
+	// Record is the name of the struct counted or summed; every record is
+	// counted when empty
+	Record string
+	Field  string
+	Scope  Scope
+}
+
+// parseSum parses the Record.Field operand of the sum modifier
+func parseSum(value string) (Aggregate, error) {
+	record, field, ok := strings.Cut(value, ".")
+
+	if !ok || record == "" || field == "" {
+		return Aggregate{}, fmt.Errorf("%w: sum %q should be Record.Field", ErrInvalidTag, value)
+	}
+
+	return Aggregate{Kind: AggregateSum, Record: record, Field: field}, nil
+}
+
+// totals accumulates the counts and sums of the records in a scope. Sums of
+// integer fields are kept in the minor units of their decimals=N modifier.
+type totals struct {
+	records  int
+	counts   map[string]int
+	ints     map[string]int64
+	decimals map[string]int
+	floats   map[string]float64
+}
+
+func (t *totals) add(rv reflect.Value, s *schema) {
+	if t.counts == nil {
+		t.counts = make(map[string]int)
+		t.ints = make(map[string]int64)
+		t.decimals = make(map[string]int)
+		t.floats = make(map[string]float64)
+	}
+
+	t.records++
+	t.counts[rv.Type().Name()]++
+
+	for _, f := range s.fields {
+		i, fl, integer, ok := f.addend(rv)
+
+		switch {
+		case !ok:
+		case integer:
+			t.ints[f.sumKey] += i
+			t.decimals[f.sumKey] = f.Decimals
+		default:
+			t.floats[f.sumKey] += fl
+		}
+	}
+}
+
+// addend returns what the field f of rv adds to its sum, as an integer in
+// the minor units of its decimals or as a float. ok is false for fields that
+// are not summed or hold no value.
+func (f *field) addend(rv reflect.Value) (i int64, fl float64, integer, ok bool) {
+	if !f.numeric || f.Filler {
+		return 0, 0, false, false
+	}
+
+	v, ok := f.value(rv, false)
+
+	switch {
+	case !ok:
+		return 0, 0, false, false
+	case f.unsigned:
+		return int64(v.Uint()), 0, true, true
+	case v.CanInt():
+		return v.Int(), 0, true, true
+	}
+
+	return 0, v.Float(), false, true
+}
+
+// aggregator keeps the totals of the file and of the current batch
+type aggregator struct {
+	file totals
+	lot  totals
+
+	// skipped is set when records went by before any type with totals was
+	// known, so the totals are incomplete
+	skipped bool
+}
+
+// observe adds the record rv, of schema s, to the totals; a batch header
+// starts the totals of a new batch
+func (a *aggregator) observe(rv reflect.Value, s *schema, batch reflect.Type) {
+	if !aggregated.Load() {
+		a.skipped = true
+		return
+	}
+
+	if rv.Type() == batch {
+		a.lot = totals{}
+	}
+
+	a.file.add(rv, s)
+	a.lot.add(rv, s)
+}
+
+// complete returns an error when the totals of a record of schema s were
+// not kept from the first record
+func (a *aggregator) complete(rv reflect.Value, s *schema) error {
+	if !s.totals || !a.skipped {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %v was first seen after other records, declare it with SetTrailer or UseTrailer", ErrAggregate, rv.Type())
+}
+
+// value returns the total of g as a value of type t, counting the record
+// rv, of schema s, which is not observed yet. Integer fields receive the
+// total in the minor units of their decimals.
+func (a *aggregator) value(g Aggregate, t reflect.Type, decimals int, rv reflect.Value, s *schema, batch reflect.Type) reflect.Value {
+	tt := &a.file
+	if g.Scope == ScopeBatch {
+		tt = &a.lot
+
+		if rv.Type() == batch {
+			tt = &totals{}
+		}
+	}
+
+	var i int64
+	var f float64
+
+	// scale is the number of decimals of i; counts are never scaled
+	scale := decimals
+	name := rv.Type().Name()
+
+	switch {
+	case g.Kind == AggregateSum:
+		key := g.Record + "." + g.Field
+		i, f, scale = tt.ints[key], tt.floats[key], tt.decimals[key]
+
+		if name != g.Record {
+			break
+		}
+
+		for _, sf := range s.fields {
+			if sf.Name != g.Field {
+				continue
+			}
+
+			pi, pf, integer, ok := sf.addend(rv)
+
+			switch {
+			case !ok:
+			case integer:
+				i += pi
+				scale = sf.Decimals
+			default:
+				f += pf
+			}
+		}
+	case g.Record == "":
+		i = int64(tt.records) + 1
+	case g.Record == name:
+		i = int64(tt.counts[g.Record]) + 1
+	default:
+		i = int64(tt.counts[g.Record])
+	}
+
+	v := reflect.New(t).Elem()
+
+	if v.CanFloat() {
+		if g.Kind != AggregateSum {
+			scale = 0
+		}
+
+		v.SetFloat(float64(i)/math.Pow10(scale) + f)
+		return v
+	}
+
+	// rescale the integer sum to the decimals of the field and add the
+	// float sum in the same minor units
+	n := i
+
+	for ; scale < decimals; scale++ {
+		n *= 10
+	}
+
+	if scale > decimals {
+		n = int64(math.Round(float64(n) / math.Pow10(scale-decimals)))
+	}
+
+	n += int64(math.Round(f * math.Pow10(decimals)))
+
+	if v.CanInt() {
+		v.SetInt(n)
+	} else {
+		v.SetUint(uint64(n))
+	}
+
+	return v
+}
+
+// check verifies that the aggregate fields of rv, of schema s, match the
+// totals, as they would be written
+func (a *aggregator) check(rv reflect.Value, s *schema, batch reflect.Type) error {
+	if err := a.complete(rv, s); err != nil {
+		return err
+	}
+
+	for _, f := range s.fields {
+		if f.Aggregate.Kind == AggregateNone {
+			continue
+		}
+
+		v, ok := f.value(rv, false)
+
+		if !ok {
+			v = reflect.Zero(f.typ)
+		}
+
+		got, err := f.encode(v, f.Tag)
+
+		if err != nil {
+			return f.error(got, err)
+		}
+
+		expected, err := f.encode(a.value(f.Aggregate, f.typ, f.Decimals, rv, s, batch), f.Tag)
+
+		if err != nil {
+			return f.error(got, err)
+		}
+
+		if got != expected {
+			return f.error(got, fmt.Errorf("%w: expected %s", ErrAggregate, expected))
+		}
+	}
+
+	return nil
+}
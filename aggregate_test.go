@@ -0,0 +1,216 @@
+package positional_line_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vert-capital/positional_line"
+)
+
+type totalHeader struct {
+	Type string `positional:"1"`
+	Name string `positional:"5"`
+}
+
+type totalDetail struct {
+	Type   string  `positional:"1"`
+	Amount float64 `positional:"7,decimals=2,leftpad,zerofill"`
+	Units  int     `positional:"3,leftpad,zerofill"`
+}
+
+type totalTrailer struct {
+	Type    string  `positional:"1"`
+	Records int     `positional:"4,leftpad,zerofill,count"`
+	Details int     `positional:"4,leftpad,zerofill,count=totalDetail"`
+	Amount  float64 `positional:"9,decimals=2,leftpad,zerofill,sum=totalDetail.Amount"`
+	Units   uint    `positional:"5,leftpad,zerofill,sum=totalDetail.Units"`
+}
+
+var totalRecords = map[string]interface{}{
+	"0": totalHeader{},
+	"1": totalDetail{},
+	"9": totalTrailer{},
+}
+
+const totalTestFile = "0ACME \n10000123002\n10000001005\n90004000200000012400007"
+
+func TestMarshalTotals(t *testing.T) {
+	result, err := positional_line.Marshal([]interface{}{
+		totalHeader{"0", "ACME"},
+		totalDetail{"1", 1.23, 2},
+		totalDetail{"1", 0.01, 5},
+		totalTrailer{Type: "9", Records: 99},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, totalTestFile, result)
+}
+
+func TestDecoderTotals(t *testing.T) {
+	dec := positional_line.NewDecoder(strings.NewReader(totalTestFile))
+	dec.UseLayout(newTestLayout(t, totalRecords))
+
+	var records []interface{}
+
+	assert.Nil(t, dec.Decode(&records))
+	assert.Equal(t, totalTrailer{"9", 4, 2, 1.24, 7}, records[3])
+}
+
+func TestDecoderTotalsMismatch(t *testing.T) {
+	dec := positional_line.NewDecoder(strings.NewReader("0ACME \n10000123002\n90003000100000012500002"))
+	dec.UseLayout(newTestLayout(t, totalRecords))
+
+	var records []interface{}
+
+	err := dec.Decode(&records)
+
+	var fieldErr *positional_line.FieldError
+
+	assert.ErrorIs(t, err, positional_line.ErrAggregate)
+	assert.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "Amount", fieldErr.Field)
+	assert.Equal(t, `posline: line 3: field Amount (columns 10-18) "125": posline: total does not match the records: expected 123`, err.Error())
+}
+
+type totalBatchTrailer struct {
+	Type    string `positional:"1"`
+	Records int    `positional:"4,leftpad,zerofill,count,scope=batch"`
+	Units   int    `positional:"5,leftpad,zerofill,sum=totalDetail.Units,scope=batch"`
+}
+
+func TestEncoderTotalsByBatch(t *testing.T) {
+	var out strings.Builder
+
+	enc := positional_line.NewEncoder(&out)
+	enc.SetBatchHeader(totalHeader{})
+
+	assert.Nil(t, enc.Encode([]interface{}{
+		totalHeader{"0", "A"},
+		totalDetail{"1", 1, 2},
+		totalBatchTrailer{Type: "5"},
+		totalHeader{"0", "B"},
+		totalDetail{"1", 1, 3},
+		totalDetail{"1", 1, 4},
+		totalBatchTrailer{Type: "5"},
+	}))
+
+	lines := strings.Split(out.String(), "\n")
+
+	assert.Equal(t, "5000300002", lines[2])
+	assert.Equal(t, "5000400007", lines[6])
+}
+
+func TestParseTagsInvalidTotals(t *testing.T) {
+	type notNumber struct {
+		Records string `positional:"4,count"`
+	}
+
+	type badSum struct {
+		Amount int `positional:"4,sum=Amount"`
+	}
+
+	type badScope struct {
+		Records int `positional:"4,count,scope=lot"`
+	}
+
+	_, err := positional_line.Marshal(notNumber{})
+	assert.ErrorIs(t, err, positional_line.ErrInvalidTag)
+
+	_, err = positional_line.Marshal(badSum{})
+	assert.ErrorIs(t, err, positional_line.ErrInvalidTag)
+
+	_, err = positional_line.Marshal(badScope{})
+	assert.ErrorIs(t, err, positional_line.ErrInvalidTag)
+}
+
+type minorDetail struct {
+	Type   string  `positional:"1"`
+	Amount float64 `positional:"7,decimals=2,leftpad,zerofill"`
+	Cents  int     `positional:"5,decimals=2,leftpad,zerofill"`
+}
+
+type minorTrailer struct {
+	Type   string  `positional:"1"`
+	Total  int64   `positional:"8,decimals=2,leftpad,zerofill,sum=minorDetail.Amount"`
+	Cents  float64 `positional:"8,decimals=2,leftpad,zerofill,sum=minorDetail.Cents"`
+	Whole  int     `positional:"4,leftpad,zerofill,sum=minorDetail.Cents"`
+	Copies int     `positional:"4,decimals=2,leftpad,zerofill,count=minorDetail"`
+}
+
+func TestTotalsInMinorUnits(t *testing.T) {
+	result, err := positional_line.Marshal([]interface{}{
+		minorDetail{"1", 1.23, 150},
+		minorDetail{"1", 0.01, 75},
+		minorTrailer{Type: "9"},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "1000012300150\n1000000100075\n9"+"00000124"+"00000225"+"0002"+"0002", result)
+
+	layout := positional_line.NewLayout(1, 1)
+
+	assert.Nil(t, layout.Register("1", minorDetail{}))
+	assert.Nil(t, layout.Register("9", minorTrailer{}))
+
+	dec := positional_line.NewDecoder(strings.NewReader("1000012300150\n1000000100075\n9" + "00000001" + "00000225" + "0002" + "0002"))
+	dec.UseLayout(layout)
+
+	var records []interface{}
+
+	err = dec.Decode(&records)
+
+	var fieldErr *positional_line.FieldError
+
+	assert.ErrorIs(t, err, positional_line.ErrAggregate)
+	assert.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "Total", fieldErr.Field)
+}
+
+func TestTotalsAcrossCalls(t *testing.T) {
+	var out strings.Builder
+
+	enc := positional_line.NewEncoder(&out)
+	enc.SetTrailer(totalTrailer{})
+
+	assert.Nil(t, enc.Encode(totalHeader{"0", "ACME"}))
+	assert.Nil(t, enc.Encode(totalDetail{"1", 1.23, 2}))
+	assert.Nil(t, enc.Encode(totalDetail{"1", 0.01, 5}))
+	assert.Nil(t, enc.Encode(totalTrailer{Type: "9"}))
+	assert.Equal(t, totalTestFile, out.String())
+
+	dec := positional_line.NewDecoder(strings.NewReader(totalTestFile))
+	dec.UseTrailer(totalTrailer{})
+
+	var header totalHeader
+	var detail totalDetail
+	var trailer totalTrailer
+
+	assert.Nil(t, dec.Decode(&header))
+	assert.Nil(t, dec.Decode(&detail))
+	assert.Nil(t, dec.Decode(&detail))
+	assert.Nil(t, dec.Decode(&trailer))
+	assert.Equal(t, totalTrailer{"9", 4, 2, 1.24, 7}, trailer)
+}
+
+func TestEncoderTotalsLeaveRecordsUntouched(t *testing.T) {
+	type totals struct {
+		N int `positional:"3,leftpad,zerofill,count"`
+	}
+
+	type trailer struct {
+		Type string `positional:"1"`
+		Tot  *totals
+	}
+
+	orig := &totals{}
+
+	result, err := positional_line.Marshal([]interface{}{
+		totalHeader{"0", "ACME"},
+		trailer{"9", orig},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "0ACME \n9002", result)
+	assert.Equal(t, 0, orig.N)
+}
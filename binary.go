@@ -0,0 +1,218 @@
+package positional_line
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Encoding tells how the content of a field is stored in the record
+type Encoding int
+
+const (
+	// EncodingText stores the content as padded characters
+	EncodingText Encoding = iota
+	// EncodingComp3 stores numbers as packed decimals (COBOL COMP-3): two
+	// digits per byte and the sign in the last nibble, so a field of size N
+	// bytes holds 2N-1 digits. Floats need decimals=N to be packed.
+	EncodingComp3
+	// EncodingBinary stores integers as big-endian two's complement (COBOL
+	// COMP) in a field of 1 to 8 bytes
+	EncodingBinary
+)
+
+// MarshalBytes encodes a struct or slice of structs as fixed-length records
+// written back to back, without line terminators, so fields may hold binary
+// content. Sizes are counted in bytes.
+func MarshalBytes(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := NewEncoder(&buf)
+	enc.SetLineTerminator("")
+	enc.opts.unit = WidthBytes
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBytes decodes fixed-length records written back to back into a
+// struct or a slice of structs
+func UnmarshalBytes(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return ErrInvalidTarget
+	}
+
+	rv = rv.Elem()
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		if err := unmarshalStruct(string(data), rv, &decodeOptions{unit: WidthBytes}); err != nil {
+			return &LineError{Line: 1, Err: err}
+		}
+	case reflect.Slice:
+		t := rv.Type().Elem()
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		s, err := cachedSchema(t)
+
+		if err != nil {
+			return err
+		}
+
+		if s.size == 0 || len(data)%s.size != 0 {
+			return fmt.Errorf("%w: %d bytes is not a multiple of the record size %d", ErrInvalidLineSize, len(data), s.size)
+		}
+
+		for i := 0; i < len(data)/s.size; i++ {
+			elem, target := newElem(rv.Type().Elem())
+			if err := s.decode(target, string(data[i*s.size:(i+1)*s.size]), &decodeOptions{unit: WidthBytes}); err != nil {
+				return &LineError{Line: i + 1, Err: err}
+			}
+			rv.Set(reflect.Append(rv, elem))
+		}
+	default:
+		return ErrUnsupportedType
+	}
+
+	return nil
+}
+
+// pack turns the numeric content of f into its binary encoding
+func (f *field) pack(content string) (string, error) {
+	if f.Encoding == EncodingComp3 {
+		return packComp3(content, f.Size)
+	}
+
+	if f.unsigned {
+		n, err := strconv.ParseUint(content, 10, 64)
+
+		if err != nil {
+			return "", err
+		}
+
+		if f.Size < 8 && n >= 1<<(8*f.Size) {
+			return "", fmt.Errorf("%w: %d does not fit in %d bytes", ErrOverflow, n, f.Size)
+		}
+
+		return packBinary(n, f.Size), nil
+	}
+
+	n, err := strconv.ParseInt(content, 10, 64)
+
+	if err != nil {
+		return "", err
+	}
+
+	if f.Size < 8 && (n < -(1<<(8*f.Size-1)) || n >= 1<<(8*f.Size-1)) {
+		return "", fmt.Errorf("%w: %d does not fit in %d bytes", ErrOverflow, n, f.Size)
+	}
+
+	return packBinary(uint64(n), f.Size), nil
+}
+
+// unpack turns the binary encoding of f back into numeric content
+func (f *field) unpack(raw string) (string, error) {
+	if f.Encoding == EncodingComp3 {
+		return unpackComp3(raw)
+	}
+
+	if len(raw) > 8 {
+		return "", fmt.Errorf("%w: %d bytes in a binary field", ErrOverflow, len(raw))
+	}
+
+	var n uint64
+
+	for i := 0; i < len(raw); i++ {
+		n = n<<8 | uint64(raw[i])
+	}
+
+	if f.unsigned {
+		return strconv.FormatUint(n, 10), nil
+	}
+
+	// sign extend the value from the field size to 64 bits
+	shift := 64 - 8*len(raw)
+
+	return strconv.FormatInt(int64(n<<shift)>>shift, 10), nil
+}
+
+func packBinary(n uint64, size int) string {
+	b := make([]byte, size)
+
+	for i := size - 1; i >= 0; i-- {
+		b[i] = byte(n)
+		n >>= 8
+	}
+
+	return string(b)
+}
+
+func packComp3(content string, size int) (string, error) {
+	sign := byte(0x0C)
+
+	if strings.HasPrefix(content, "-") {
+		sign = 0x0D
+		content = content[1:]
+	}
+
+	if strings.Trim(content, "0123456789") != "" {
+		return "", fmt.Errorf("posline: %q is not a number", content)
+	}
+
+	if len(content) > 2*size-1 {
+		return "", fmt.Errorf("%w: %d digits in a packed field of %d bytes", ErrOverflow, len(content), size)
+	}
+
+	digits := strings.Repeat("0", 2*size-1-len(content)) + content
+
+	nibbles := make([]byte, 0, 2*size)
+
+	for i := 0; i < len(digits); i++ {
+		nibbles = append(nibbles, digits[i]-'0')
+	}
+
+	nibbles = append(nibbles, sign)
+
+	b := make([]byte, size)
+
+	for i := range b {
+		b[i] = nibbles[2*i]<<4 | nibbles[2*i+1]
+	}
+
+	return string(b), nil
+}
+
+func unpackComp3(raw string) (string, error) {
+	var digits strings.Builder
+
+	for i := 0; i < len(raw); i++ {
+		high, low := raw[i]>>4, raw[i]&0x0F
+
+		if high > 9 || (i < len(raw)-1 && low > 9) {
+			return "", fmt.Errorf("posline: invalid packed digit in byte %#02x", raw[i])
+		}
+
+		digits.WriteByte('0' + high)
+
+		if i < len(raw)-1 {
+			digits.WriteByte('0' + low)
+		}
+	}
+
+	switch raw[len(raw)-1] & 0x0F {
+	case 0x0A, 0x0C, 0x0E, 0x0F:
+		return digits.String(), nil
+	case 0x0B, 0x0D:
+		return "-" + digits.String(), nil
+	}
+
+	return "", fmt.Errorf("%w: packed sign nibble %#x", ErrInvalidSign, raw[len(raw)-1]&0x0F)
+}
@@ -0,0 +1,115 @@
+package positional_line_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vert-capital/positional_line"
+)
+
+type binaryTestStruct struct {
+	Code     string  `positional:"3"`
+	Amount   float64 `positional:"4,comp3,decimals=2"`
+	Quantity int32   `positional:"4,binary"`
+	Units    uint16  `positional:"2,binary"`
+	Balance  int64   `positional:"3,comp3"`
+}
+
+func TestMarshalBytes(t *testing.T) {
+	records := []binaryTestStruct{
+		{"ABC", 12345.67, -2, 513, -42},
+		{"DEF", -0.5, 10, 65535, 0},
+	}
+
+	data, err := positional_line.MarshalBytes(records)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{
+		'A', 'B', 'C', 0x12, 0x34, 0x56, 0x7C, 0xFF, 0xFF, 0xFF, 0xFE, 0x02, 0x01, 0x00, 0x04, 0x2D,
+		'D', 'E', 'F', 0x00, 0x00, 0x05, 0x0D, 0x00, 0x00, 0x00, 0x0A, 0xFF, 0xFF, 0x00, 0x00, 0x0C,
+	}, data)
+
+	var output []binaryTestStruct
+
+	assert.Nil(t, positional_line.UnmarshalBytes(data, &output))
+	assert.Equal(t, records, output)
+}
+
+func TestUnmarshalBytesStruct(t *testing.T) {
+	var output binaryTestStruct
+
+	data := []byte{'X', ' ', ' ', 0x00, 0x00, 0x01, 0x0F, 0x80, 0x00, 0x00, 0x00, 0x00, 0x0A, 0x00, 0x12, 0x3B}
+
+	assert.Nil(t, positional_line.UnmarshalBytes(data, &output))
+	assert.Equal(t, binaryTestStruct{"X", 0.1, -2147483648, 10, -123}, output)
+}
+
+func TestUnmarshalBytesPointers(t *testing.T) {
+	var output []*binaryTestStruct
+
+	data := []byte{'X', ' ', ' ', 0x00, 0x00, 0x01, 0x0F, 0x80, 0x00, 0x00, 0x00, 0x00, 0x0A, 0x00, 0x12, 0x3B}
+
+	assert.Nil(t, positional_line.UnmarshalBytes(data, &output))
+	assert.Equal(t, []*binaryTestStruct{{"X", 0.1, -2147483648, 10, -123}}, output)
+}
+
+func TestMarshalBytesOverflow(t *testing.T) {
+	_, err := positional_line.MarshalBytes(binaryTestStruct{Amount: 123456.78})
+	assert.ErrorIs(t, err, positional_line.ErrOverflow)
+
+	type TestStruct struct {
+		Small int16 `positional:"1,binary"`
+	}
+
+	_, err = positional_line.MarshalBytes(TestStruct{128})
+	assert.ErrorIs(t, err, positional_line.ErrOverflow)
+
+	data, err := positional_line.MarshalBytes(TestStruct{-128})
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x80}, data)
+}
+
+func TestUnmarshalBytesErrors(t *testing.T) {
+	var output []binaryTestStruct
+
+	err := positional_line.UnmarshalBytes(make([]byte, 17), &output)
+	assert.ErrorIs(t, err, positional_line.ErrInvalidLineSize)
+
+	data := []byte{'X', ' ', ' ', 0x00, 0x00, 0x01, 0x01, 0x80, 0x00, 0x00, 0x00, 0x00, 0x0A, 0x00, 0x12, 0x3B}
+
+	err = positional_line.UnmarshalBytes(data, &output)
+	assert.ErrorIs(t, err, positional_line.ErrInvalidSign)
+
+	var fieldErr *positional_line.FieldError
+	assert.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "Amount", fieldErr.Field)
+}
+
+func TestBinaryInvalidTag(t *testing.T) {
+	type TestStruct struct {
+		Big int64 `positional:"9,binary"`
+	}
+
+	_, err := positional_line.MarshalBytes(TestStruct{})
+	assert.ErrorIs(t, err, positional_line.ErrInvalidTag)
+}
+
+func TestUnmarshalBinaryInRunes(t *testing.T) {
+	type record struct {
+		A int32 `positional:"4,binary"`
+	}
+
+	var output record
+
+	err := positional_line.Unmarshal("€€€€", &output)
+
+	assert.ErrorIs(t, err, positional_line.ErrBinaryWidth)
+
+	data, err := positional_line.Marshal(binaryTestStruct{"ABC", 1.5, -2, 513, -42})
+
+	assert.Nil(t, err)
+
+	var records []binaryTestStruct
+
+	assert.ErrorIs(t, positional_line.Unmarshal(data, &records), positional_line.ErrBinaryWidth)
+}
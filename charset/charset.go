@@ -0,0 +1,170 @@
+// Package charset transcodes UTF-8 text to and from single-byte character
+// sets, such as the Windows-1252, ISO-8859-1 and EBCDIC files exchanged with
+// banks and mainframes.
+package charset
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// ErrUnmappable is raised when a rune has no byte in the character set
+var ErrUnmappable = errors.New("charset: rune not representable")
+
+// Charset is a single-byte character set: every byte is one character
+type Charset struct {
+	name   string
+	decode [256]rune
+	encode map[rune]byte
+}
+
+var (
+	// ISO88591 is ISO-8859-1 (Latin-1)
+	ISO88591 = newCharset("ISO-8859-1", latin1())
+	// Windows1252 is the Windows Western European code page
+	Windows1252 = newCharset("Windows-1252", windows1252)
+	// CP037 is the EBCDIC code page for US and Canada
+	CP037 = newCharset("CP037", cp037)
+	// CP500 is the international EBCDIC code page
+	CP500 = newCharset("CP500", cp500)
+)
+
+func newCharset(name string, decode [256]rune) *Charset {
+	c := &Charset{
+		name:   name,
+		decode: decode,
+		encode: make(map[rune]byte, 256),
+	}
+
+	for b, r := range decode {
+		c.encode[r] = byte(b)
+	}
+
+	return c
+}
+
+func latin1() [256]rune {
+	var t [256]rune
+
+	for i := range t {
+		t[i] = rune(i)
+	}
+
+	return t
+}
+
+func (c *Charset) String() string {
+	return c.name
+}
+
+// Encode converts UTF-8 text into bytes of the character set
+func (c *Charset) Encode(s string) ([]byte, error) {
+	b := make([]byte, 0, len(s))
+
+	for _, r := range s {
+		e, ok := c.encode[r]
+
+		if !ok {
+			return nil, fmt.Errorf("%w: %q in %s", ErrUnmappable, r, c.name)
+		}
+
+		b = append(b, e)
+	}
+
+	return b, nil
+}
+
+// Decode converts bytes of the character set into UTF-8 text
+func (c *Charset) Decode(b []byte) string {
+	s := make([]byte, 0, len(b))
+
+	for _, e := range b {
+		s = utf8.AppendRune(s, c.decode[e])
+	}
+
+	return string(s)
+}
+
+// NewReader returns a reader that decodes the bytes read from r into UTF-8
+func (c *Charset) NewReader(r io.Reader) io.Reader {
+	return &reader{r: r, c: c}
+}
+
+// NewWriter returns a writer that encodes UTF-8 text written to it into the
+// character set before writing it to w
+func (c *Charset) NewWriter(w io.Writer) io.Writer {
+	return &writer{w: w, c: c}
+}
+
+type reader struct {
+	r   io.Reader
+	c   *Charset
+	buf [4096]byte
+	out []byte
+	err error
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 && r.err == nil {
+		n, err := r.r.Read(r.buf[:])
+
+		r.out = r.out[:0]
+		for _, e := range r.buf[:n] {
+			r.out = utf8.AppendRune(r.out, r.c.decode[e])
+		}
+
+		r.err = err
+	}
+
+	if len(r.out) == 0 {
+		return 0, r.err
+	}
+
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+
+	return n, nil
+}
+
+type writer struct {
+	w       io.Writer
+	c       *Charset
+	pending []byte
+}
+
+// Write encodes p, keeping an incomplete trailing rune until the next call
+func (w *writer) Write(p []byte) (int, error) {
+	data := p
+
+	if len(w.pending) > 0 {
+		data = append(w.pending, p...)
+		w.pending = nil
+	}
+
+	out := make([]byte, 0, len(data))
+
+	for len(data) > 0 && utf8.FullRune(data) {
+		r, size := utf8.DecodeRune(data)
+
+		e, ok := w.c.encode[r]
+
+		if !ok {
+			return 0, fmt.Errorf("%w: %q in %s", ErrUnmappable, r, w.c.name)
+		}
+
+		out = append(out, e)
+		data = data[size:]
+	}
+
+	if len(data) > 0 {
+		w.pending = append([]byte(nil), data...)
+	}
+
+	if _, err := w.w.Write(out); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,72 @@
+package charset_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/vert-capital/positional_line/charset"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		charset *charset.Charset
+		text    string
+		encoded []byte
+	}{
+		{charset.ISO88591, "Ação\n", []byte{0x41, 0xE7, 0xE3, 0x6F, 0x0A}},
+		{charset.Windows1252, "€ “ok”", []byte{0x80, 0x20, 0x93, 0x6F, 0x6B, 0x94}},
+		{charset.CP037, "AÇÃO 1\n", []byte{0xC1, 0x68, 0x66, 0xD6, 0x40, 0xF1, 0x25}},
+		{charset.CP500, "[ok]!", []byte{0x4A, 0x96, 0x92, 0x5A, 0x4F}},
+	}
+
+	for _, test := range tests {
+		encoded, err := test.charset.Encode(test.text)
+		if err != nil {
+			t.Errorf("%s.Encode(%q) returned error %v", test.charset, test.text, err)
+		}
+		if !bytes.Equal(encoded, test.encoded) {
+			t.Errorf("%s.Encode(%q) = %x; want %x", test.charset, test.text, encoded, test.encoded)
+		}
+
+		decoded := test.charset.Decode(test.encoded)
+		if decoded != test.text {
+			t.Errorf("%s.Decode(%x) = %q; want %q", test.charset, test.encoded, decoded, test.text)
+		}
+	}
+}
+
+func TestEncodeUnmappable(t *testing.T) {
+	if _, err := charset.ISO88591.Encode("€"); err == nil {
+		t.Errorf("Expected error for unmappable rune, but got none")
+	}
+}
+
+func TestReaderWriter(t *testing.T) {
+	text := strings.Repeat("Conceição 123\n", 1000)
+
+	var out bytes.Buffer
+
+	w := charset.CP037.NewWriter(&out)
+
+	// write one byte at a time to split the multi-byte runes
+	for i := 0; i < len(text); i++ {
+		if _, err := w.Write([]byte{text[i]}); err != nil {
+			t.Fatalf("Write returned error %v", err)
+		}
+	}
+
+	if out.Len() != len([]rune(text)) {
+		t.Errorf("Writer wrote %d bytes; want %d", out.Len(), len([]rune(text)))
+	}
+
+	decoded, err := io.ReadAll(charset.CP037.NewReader(&out))
+	if err != nil {
+		t.Fatalf("Reader returned error %v", err)
+	}
+
+	if string(decoded) != text {
+		t.Errorf("Reader did not restore the original text")
+	}
+}
@@ -0,0 +1,397 @@
+package positional_line
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vert-capital/positional_line/charset"
+)
+
+// Decoder reads positional lines from an input stream, one record at a time
+type Decoder struct {
+	src    io.Reader
+	r      *bufio.Reader
+	layout *Layout
+	line   int
+	fixed  bool
+	opts   decodeOptions
+	seq    sequence
+	batch  reflect.Type
+	total  aggregator
+
+	collect  bool
+	rejects  io.Writer
+	warnings []*LineError
+}
+
+// NewDecoder returns a new decoder that reads from r
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{src: r, r: bufio.NewReader(r), seq: sequence{start: 1}}
+}
+
+// UseSequence checks the seq fields against numbers from start, counting
+// every record of the file or, with ScopeBatch, the records after each
+// batch header. By default records are expected to be numbered from 1 over
+// the whole file.
+func (d *Decoder) UseSequence(start int, scope Scope) {
+	d.seq = sequence{start: start, scope: scope}
+}
+
+// UseTrailer declares the record struct, of the type of v, whose fields
+// hold totals, so that they are kept from the first record. It is only
+// needed when the trailer is not registered in the layout in use.
+func (d *Decoder) UseTrailer(v interface{}) {
+	cachedSchema(recordOf(v))
+}
+
+// UseBatchHeader sets the record struct, of the type of v or of what it
+// points to, that starts every batch, for sequences and totals scoped to a
+// batch
+func (d *Decoder) UseBatchHeader(v interface{}) {
+	d.batch = recordOf(v)
+}
+
+// UseCharset makes the decoder read input encoded in c, counting field
+// widths in its single-byte characters. It must be called before the first
+// Decode.
+func (d *Decoder) UseCharset(c *charset.Charset) {
+	d.r = bufio.NewReader(c.NewReader(d.src))
+	d.opts.unit = WidthRunes
+}
+
+// UseWidthUnit sets the unit in which field sizes are counted, WidthRunes
+// by default
+func (d *Decoder) UseWidthUnit(u WidthUnit) {
+	d.opts.unit = u
+}
+
+// UseLayout makes the decoder dispatch each line to the record struct
+// registered in l, allowing Decode into an interface or a slice of interfaces
+func (d *Decoder) UseLayout(l *Layout) {
+	d.layout = l
+}
+
+// CollectErrors makes Decode into a slice keep going past lines that fail to
+// decode. Every valid record is appended and the failures are returned
+// together as an ErrorList once the input is exhausted.
+func (d *Decoder) CollectErrors() {
+	d.collect = true
+}
+
+// Rejects makes the decoder write every line that fails to decode to w,
+// followed by a tab and the reason of the failure
+func (d *Decoder) Rejects(w io.Writer) {
+	d.rejects = w
+}
+
+// AllowShortLines makes the decoder accept lines shorter than their record,
+// such as lines whose trailing blanks were stripped, treating the missing
+// positions as spaces. Each one is reported in Warnings.
+func (d *Decoder) AllowShortLines() {
+	d.opts.allowShort = true
+	d.opts.warn = d.warning
+}
+
+// AllowLongLines makes the decoder ignore the content beyond the end of the
+// record. Each line with extra content is reported in Warnings.
+func (d *Decoder) AllowLongLines() {
+	d.opts.allowLong = true
+	d.opts.warn = d.warning
+}
+
+// Warnings returns the lines that were decoded despite a tolerated problem
+func (d *Decoder) Warnings() []*LineError {
+	return d.warnings
+}
+
+func (d *Decoder) warning(err error) {
+	d.warnings = append(d.warnings, &LineError{Line: d.line, Err: err})
+}
+
+// UseFixedLength makes the decoder split the input into records of exactly
+// the width of their struct, for files whose records are written back to
+// back without line terminators. With a layout, the discriminator of each
+// record decides its struct and so its width.
+func (d *Decoder) UseFixedLength() {
+	d.fixed = true
+}
+
+// More reports whether there is another line to be decoded
+func (d *Decoder) More() bool {
+	_, err := d.r.Peek(1)
+	return err == nil
+}
+
+// Decode reads the next line into the struct pointed to by v. When v points
+// to a slice, every remaining line is decoded and appended to it. If a layout
+// is in use, v may also point to an interface (or a slice of interfaces),
+// which receives a value of the record struct matching the line. Decode
+// returns io.EOF when there are no more lines.
+//
+// A line that fails to decode is consumed, so decoding one record at a time
+// can move on to the next line after an error.
+func (d *Decoder) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return ErrInvalidTarget
+	}
+
+	rv = rv.Elem()
+
+	switch rv.Kind() {
+	case reflect.Struct, reflect.Interface:
+		line, err := d.readRecord(rv)
+
+		if err != nil {
+			return err
+		}
+
+		return d.decodeLine(line, rv)
+	case reflect.Slice:
+		var errs ErrorList
+
+		sliceType := rv.Type().Elem()
+		for d.More() {
+			elem, target := newElem(sliceType)
+
+			line, err := d.readRecord(target)
+
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				return err
+			}
+
+			if err := d.decodeLine(line, target); err != nil {
+				lineErr, ok := err.(*LineError)
+
+				if !d.collect || !ok {
+					return err
+				}
+
+				errs = append(errs, lineErr)
+				continue
+			}
+			rv.Set(reflect.Append(rv, elem))
+		}
+
+		if len(errs) > 0 {
+			return errs
+		}
+	default:
+		return ErrUnsupportedType
+	}
+
+	return nil
+}
+
+// decodeLine unmarshals the last line read into rv, reporting errors with
+// the line number and writing the rejected line out
+func (d *Decoder) decodeLine(line string, rv reflect.Value) error {
+	t, err := d.recordType(line, rv)
+
+	// every line takes a number, so that a line that is not decoded does
+	// not shift the numbers expected on the lines after it
+	n, numbered := d.seq.number(t, d.batch)
+
+	if err == nil {
+		err = d.decodeRecord(line, rv, t, n, numbered)
+	}
+
+	if err == nil {
+		return nil
+	}
+
+	lineErr := &LineError{Line: d.line, Err: err}
+
+	if d.rejects != nil {
+		if _, err := fmt.Fprintf(d.rejects, "%s\t%v\n", line, lineErr); err != nil {
+			return err
+		}
+	}
+
+	return lineErr
+}
+
+// recordType returns the record struct of line, resolved through the
+// layout when rv is an interface
+func (d *Decoder) recordType(line string, rv reflect.Value) (reflect.Type, error) {
+	if rv.Kind() != reflect.Interface {
+		return rv.Type(), nil
+	}
+
+	if d.layout == nil {
+		return nil, errors.New("posline: a layout is required to decode into an interface")
+	}
+
+	t, err := d.layout.recordType(line, d.opts.unit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !t.AssignableTo(rv.Type()) {
+		return nil, fmt.Errorf("posline: record %v is not assignable to %v", t, rv.Type())
+	}
+
+	return t, nil
+}
+
+// decodeRecord unmarshals line into rv as the record struct t, which is the
+// record number n of its sequence when numbered
+func (d *Decoder) decodeRecord(line string, rv reflect.Value, t reflect.Type, n int, numbered bool) error {
+	if rv.Kind() != reflect.Interface {
+		return d.decodeStruct(line, rv, n, numbered)
+	}
+
+	elem := reflect.New(t).Elem()
+	if err := d.decodeStruct(line, elem, n, numbered); err != nil {
+		return err
+	}
+
+	rv.Set(elem)
+
+	return nil
+}
+
+// decodeStruct unmarshals line into rv and checks its seq and total fields
+func (d *Decoder) decodeStruct(line string, rv reflect.Value, n int, numbered bool) error {
+	s, err := cachedSchema(rv.Type())
+
+	if err != nil {
+		return err
+	}
+
+	if err := s.decode(rv, line, &d.opts); err != nil {
+		return err
+	}
+
+	if numbered {
+		err = d.seq.check(rv, n)
+	}
+
+	if err == nil {
+		err = d.total.check(rv, s, d.batch)
+	}
+
+	d.total.observe(rv, s, d.batch)
+
+	return err
+}
+
+// readRecord returns the content of the next record to be decoded into rv,
+// skipping the UTF-8 byte order mark at the start of the input
+func (d *Decoder) readRecord(rv reflect.Value) (string, error) {
+	if d.line == 0 {
+		if bom, _ := d.r.Peek(len(utf8BOM)); string(bom) == utf8BOM {
+			d.r.Discard(len(utf8BOM))
+		}
+	}
+
+	if !d.fixed {
+		return d.readLine()
+	}
+
+	t := rv.Type()
+
+	if rv.Kind() == reflect.Interface && d.layout != nil {
+		var err error
+
+		prefix := d.peek(d.layout.start - 1 + d.layout.size)
+
+		if t, err = d.layout.recordType(prefix, d.opts.unit); err != nil {
+			return "", &LineError{Line: d.line + 1, Err: err}
+		}
+	}
+
+	s, err := cachedSchema(t)
+
+	if err != nil {
+		return "", &LineError{Line: d.line + 1, Err: err}
+	}
+
+	if s.size == 0 {
+		return "", &LineError{Line: d.line + 1, Err: fmt.Errorf("%w: %v has no fields to give it a width", ErrInvalidLineSize, t)}
+	}
+
+	return d.read(s.size)
+}
+
+// peek returns up to n positions of the input without consuming them
+func (d *Decoder) peek(n int) string {
+	if d.opts.unit == WidthBytes {
+		b, _ := d.r.Peek(n)
+		return string(b)
+	}
+
+	for k := n; ; {
+		b, err := d.r.Peek(k)
+		count := utf8.RuneCount(b)
+
+		if count >= n || err != nil {
+			return string([]rune(string(b))[:min(count, n)])
+		}
+
+		k += n - count
+	}
+}
+
+// read consumes a record of n positions; a shorter record at the end of the
+// input is returned as is, to be reported by the size check
+func (d *Decoder) read(n int) (string, error) {
+	var record strings.Builder
+
+	for i := 0; i < n; i++ {
+		var err error
+
+		if d.opts.unit == WidthBytes {
+			var b byte
+
+			if b, err = d.r.ReadByte(); err == nil {
+				record.WriteByte(b)
+			}
+		} else {
+			var r rune
+
+			if r, _, err = d.r.ReadRune(); err == nil {
+				record.WriteRune(r)
+			}
+		}
+
+		if err == io.EOF && record.Len() > 0 {
+			break
+		}
+
+		if err != nil {
+			return "", err
+		}
+	}
+
+	d.line++
+
+	return record.String(), nil
+}
+
+// readLine returns the next line without its "\n" or "\r\n" terminator
+func (d *Decoder) readLine() (string, error) {
+	line, err := d.r.ReadString('\n')
+
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	d.line++
+
+	return trimTerminator(line), nil
+}
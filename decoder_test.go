@@ -0,0 +1,204 @@
+package positional_line_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vert-capital/positional_line"
+	"github.com/vert-capital/positional_line/charset"
+)
+
+type decoderTestStruct struct {
+	Field1 string  `positional:"10"`
+	Field2 float64 `positional:"6,leftpad"`
+}
+
+type decoderNameStruct struct {
+	Field1 string  `positional:"10"`
+	Field2 float64 `positional:"6,leftpad"`
+	Name   string  `positional:"10"`
+}
+
+func TestDecoderDecode(t *testing.T) {
+	dec := positional_line.NewDecoder(strings.NewReader("hello     123.45\nworld       1.50\n"))
+
+	var records []decoderTestStruct
+
+	for dec.More() {
+		var r decoderTestStruct
+
+		assert.Nil(t, dec.Decode(&r))
+
+		records = append(records, r)
+	}
+
+	assert.Equal(t, []decoderTestStruct{{"hello", 123.45}, {"world", 1.5}}, records)
+
+	var r decoderTestStruct
+	assert.Equal(t, io.EOF, dec.Decode(&r))
+}
+
+func TestDecoderDecodeSlice(t *testing.T) {
+	dec := positional_line.NewDecoder(strings.NewReader("hello     123.45\nworld       1.50"))
+
+	var records []decoderTestStruct
+
+	assert.Nil(t, dec.Decode(&records))
+	assert.Equal(t, []decoderTestStruct{{"hello", 123.45}, {"world", 1.5}}, records)
+}
+
+func TestDecoderDecodeError(t *testing.T) {
+	dec := positional_line.NewDecoder(strings.NewReader("hello     abc.45"))
+
+	var r decoderTestStruct
+
+	assert.NotNil(t, dec.Decode(&r))
+	assert.NotNil(t, dec.Decode(r))
+}
+
+func TestDecoderCollectErrors(t *testing.T) {
+	data := "hello     123.45\nbad       abc.de\nworld       1.50\nshort"
+
+	var rejects strings.Builder
+
+	dec := positional_line.NewDecoder(strings.NewReader(data))
+	dec.CollectErrors()
+	dec.Rejects(&rejects)
+
+	var records []decoderTestStruct
+
+	err := dec.Decode(&records)
+
+	var errs positional_line.ErrorList
+
+	assert.ErrorAs(t, err, &errs)
+	assert.Len(t, errs, 2)
+	assert.Equal(t, 2, errs[0].Line)
+	assert.Equal(t, 4, errs[1].Line)
+	assert.ErrorIs(t, err, positional_line.ErrInvalidField)
+	assert.ErrorIs(t, err, positional_line.ErrInvalidLineSize)
+
+	assert.Equal(t, []decoderTestStruct{{"hello", 123.45}, {"world", 1.5}}, records)
+
+	rejected := strings.Split(strings.TrimSuffix(rejects.String(), "\n"), "\n")
+
+	assert.Len(t, rejected, 2)
+	assert.True(t, strings.HasPrefix(rejected[0], "bad       abc.de\tposline: line 2: "))
+	assert.True(t, strings.HasPrefix(rejected[1], "short\tposline: line 4: "))
+}
+
+func TestDecoderDecodeContinuesAfterError(t *testing.T) {
+	var rejects strings.Builder
+
+	dec := positional_line.NewDecoder(strings.NewReader("bad       abc.de\nworld       1.50"))
+	dec.Rejects(&rejects)
+
+	var r decoderTestStruct
+
+	assert.NotNil(t, dec.Decode(&r))
+	assert.Nil(t, dec.Decode(&r))
+	assert.Equal(t, decoderTestStruct{"world", 1.5}, r)
+	assert.Equal(t, 1, strings.Count(rejects.String(), "\n"))
+}
+
+func TestDecoderUseCharset(t *testing.T) {
+	data := []byte{0xC3, 0x96, 0x95, 0x83, 0x85, 0x89, 0x48, 0x46, 0x96, 0x40, 0x40, 0x40, 0xF1, 0xF2, 0x4B, 0xF5, 0x25}
+
+	dec := positional_line.NewDecoder(bytes.NewReader(data))
+	dec.UseCharset(charset.CP037)
+
+	var r decoderTestStruct
+
+	assert.Nil(t, dec.Decode(&r))
+	assert.Equal(t, decoderTestStruct{"Conceição", 12.5}, r)
+}
+
+func TestDecoderTerminatorTolerance(t *testing.T) {
+	dec := positional_line.NewDecoder(strings.NewReader("\xef\xbb\xbfhello     123.45\r\nworld       1.50\r\n"))
+
+	var records []decoderTestStruct
+
+	assert.Nil(t, dec.Decode(&records))
+	assert.Equal(t, []decoderTestStruct{{"hello", 123.45}, {"world", 1.5}}, records)
+}
+
+func TestDecoderFixedLength(t *testing.T) {
+	dec := positional_line.NewDecoder(strings.NewReader("Conceição 123.45world       1.50hello"))
+	dec.UseFixedLength()
+
+	var r decoderTestStruct
+
+	assert.Nil(t, dec.Decode(&r))
+	assert.Equal(t, decoderTestStruct{"Conceição", 123.45}, r)
+
+	assert.Nil(t, dec.Decode(&r))
+	assert.Equal(t, decoderTestStruct{"world", 1.5}, r)
+
+	assert.ErrorIs(t, dec.Decode(&r), positional_line.ErrInvalidLineSize)
+	assert.Equal(t, io.EOF, dec.Decode(&r))
+}
+
+func TestDecoderFixedLengthWithoutFields(t *testing.T) {
+	type record struct {
+		Name string
+	}
+
+	dec := positional_line.NewDecoder(strings.NewReader("hello"))
+	dec.UseFixedLength()
+
+	var records []record
+
+	assert.ErrorIs(t, dec.Decode(&records), positional_line.ErrInvalidLineSize)
+}
+
+func TestDecoderFixedLengthCharset(t *testing.T) {
+	data := []byte{0xC3, 0x96, 0x95, 0x83, 0x85, 0x89, 0x48, 0x46, 0x96, 0x40, 0x40, 0x40, 0xF1, 0xF2, 0x4B, 0xF5}
+	data = append(data, data...)
+
+	dec := positional_line.NewDecoder(bytes.NewReader(data))
+	dec.UseCharset(charset.CP037)
+	dec.UseFixedLength()
+
+	var records []decoderTestStruct
+
+	assert.Nil(t, dec.Decode(&records))
+	assert.Equal(t, []decoderTestStruct{{"Conceição", 12.5}, {"Conceição", 12.5}}, records)
+}
+
+func TestDecoderAllowShortLines(t *testing.T) {
+	dec := positional_line.NewDecoder(strings.NewReader("hello     123.45João\nworld       1.50\nfull        2.00full      "))
+	dec.AllowShortLines()
+
+	var records []decoderNameStruct
+
+	assert.Nil(t, dec.Decode(&records))
+	assert.Equal(t, []decoderNameStruct{{"hello", 123.45, "João"}, {"world", 1.5, ""}, {"full", 2, "full"}}, records)
+
+	warnings := dec.Warnings()
+
+	assert.Len(t, warnings, 2)
+	assert.Equal(t, 1, warnings[0].Line)
+	assert.Equal(t, 2, warnings[1].Line)
+	assert.ErrorIs(t, warnings[0], positional_line.ErrShortLine)
+}
+
+func TestDecoderAllowLongLines(t *testing.T) {
+	dec := positional_line.NewDecoder(strings.NewReader("hello     123.45 extra\nworld       1.50\nshort"))
+	dec.AllowLongLines()
+
+	var records []decoderTestStruct
+
+	err := dec.Decode(&records)
+
+	assert.ErrorIs(t, err, positional_line.ErrInvalidLineSize)
+	assert.Equal(t, []decoderTestStruct{{"hello", 123.45}, {"world", 1.5}}, records)
+
+	warnings := dec.Warnings()
+
+	assert.Len(t, warnings, 1)
+	assert.Equal(t, 1, warnings[0].Line)
+	assert.ErrorIs(t, warnings[0], positional_line.ErrLongLine)
+}
@@ -0,0 +1,179 @@
+package positional_line
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/vert-capital/positional_line/charset"
+)
+
+// Encoder writes positional lines to an output stream, one record at a time
+type Encoder struct {
+	out   io.Writer
+	w     io.Writer
+	sep   string
+	final bool
+	line  int
+	opts  encodeOptions
+	seq   sequence
+	batch reflect.Type
+	total aggregator
+}
+
+// NewEncoder returns a new encoder that writes to w
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{out: w, w: w, sep: "\n", seq: sequence{start: 1}}
+}
+
+// SetSequence numbers the seq fields from start, counting every record of
+// the file or, with ScopeBatch, the records after each batch header. By
+// default records are numbered from 1 over the whole file.
+func (e *Encoder) SetSequence(start int, scope Scope) {
+	e.seq = sequence{start: start, scope: scope}
+}
+
+// SetTrailer declares the record struct, of the type of v, whose fields hold
+// totals, so that they are kept from the first record. It is only needed
+// when the trailer is encoded in a later call than the records.
+func (e *Encoder) SetTrailer(v interface{}) {
+	cachedSchema(recordOf(v))
+}
+
+// SetBatchHeader sets the record struct, of the type of v or of what it
+// points to, that starts every batch, for sequences and totals scoped to a
+// batch
+func (e *Encoder) SetBatchHeader(v interface{}) {
+	e.batch = recordOf(v)
+}
+
+// SetCharset makes the encoder write its output encoded in c, so that field
+// widths are counted in its single-byte characters
+func (e *Encoder) SetCharset(c *charset.Charset) {
+	e.w = c.NewWriter(e.out)
+	e.opts.unit = WidthRunes
+}
+
+// SetNormalize applies n to every string field, on top of the upper and
+// ascii tag modifiers
+func (e *Encoder) SetNormalize(n Normalization) {
+	e.opts.normalize = n
+}
+
+// SetControlPolicy sets what to do with control characters in string
+// fields whose tag does not choose a policy; they raise an error by default
+func (e *Encoder) SetControlPolicy(p ControlPolicy) {
+	e.opts.control = p
+}
+
+// SetWidthUnit sets the unit in which field sizes are counted, WidthRunes
+// by default
+func (e *Encoder) SetWidthUnit(u WidthUnit) {
+	e.opts.unit = u
+}
+
+// SetLineTerminator sets the terminator written between records, "\n" by
+// default. Use "\r\n" for CRLF files or "" to write fixed-length records
+// back to back, as read by Decoder.UseFixedLength.
+func (e *Encoder) SetLineTerminator(t string) {
+	e.sep = t
+}
+
+// SetFinalTerminator makes the encoder write the line terminator after every
+// record, the last one included, instead of only between records
+func (e *Encoder) SetFinalTerminator(final bool) {
+	e.final = final
+}
+
+// SetOverflow sets the policy for values longer than their field, used by
+// every field whose tag does not choose one
+func (e *Encoder) SetOverflow(o Overflow) {
+	e.opts.overflow = o
+}
+
+// Encode writes the positional representation of v to the stream. v may be a
+// struct or a slice of structs, including a slice of pointers or of
+// interfaces holding different record structs; lines are separated by the
+// line terminator across calls, so the output of several Encode calls
+// matches a single Marshal of all records.
+func (e *Encoder) Encode(v interface{}) error {
+	rv := record(reflect.ValueOf(v))
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		return e.encodeStruct(rv)
+	case reflect.Slice:
+		// a trailer with totals must be known before the records it adds up
+		if !aggregated.Load() {
+			for i := 0; i < rv.Len(); i++ {
+				if r := record(rv.Index(i)); r.Kind() == reflect.Struct {
+					cachedSchema(r.Type())
+				}
+			}
+		}
+
+		for i := 0; i < rv.Len(); i++ {
+			if err := e.encodeStruct(record(rv.Index(i))); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// record unwraps the interfaces and pointers that hold a record
+func record(rv reflect.Value) reflect.Value {
+	for (rv.Kind() == reflect.Interface || rv.Kind() == reflect.Ptr) && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	return rv
+}
+
+func (e *Encoder) encodeStruct(rv reflect.Value) error {
+	if rv.Kind() != reflect.Struct {
+		return &LineError{Line: e.line + 1, Err: fmt.Errorf("%w: %v", ErrUnsupportedType, rv.Kind())}
+	}
+
+	s, err := cachedSchema(rv.Type())
+
+	if err != nil {
+		return &LineError{Line: e.line + 1, Err: err}
+	}
+
+	// the sequence and totals only move on once the record is written
+	seq := e.seq
+
+	o := e.opts
+	o.seq, o.numbered = seq.number(rv.Type(), e.batch)
+
+	if err := e.total.complete(rv, s); err != nil {
+		return &LineError{Line: e.line + 1, Err: err}
+	}
+
+	o.totals = &e.total
+	o.batch = e.batch
+
+	l, err := s.encode(rv, &o)
+
+	if err != nil {
+		return &LineError{Line: e.line + 1, Err: err}
+	}
+
+	if e.final {
+		l = l + e.sep
+	} else if e.line > 0 {
+		l = e.sep + l
+	}
+
+	if _, err := io.WriteString(e.w, l); err != nil {
+		return err
+	}
+
+	e.line++
+	e.seq = seq
+	e.total.observe(rv, s, e.batch)
+
+	return nil
+}
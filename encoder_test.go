@@ -0,0 +1,94 @@
+package positional_line_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vert-capital/positional_line"
+	"github.com/vert-capital/positional_line/charset"
+)
+
+type encoderTestStruct struct {
+	Field1 string  `positional:"10"`
+	Field2 float64 `positional:"5,nofloat,leftpad"`
+}
+
+func TestEncoderEncode(t *testing.T) {
+	var out strings.Builder
+
+	enc := positional_line.NewEncoder(&out)
+
+	assert.Nil(t, enc.Encode(encoderTestStruct{"hello", 123}))
+	assert.Nil(t, enc.Encode([]encoderTestStruct{{"456", 45}, {"world", 1}}))
+
+	assert.Equal(t, "hello     12300\n456        4500\nworld       100", out.String())
+}
+
+func TestEncoderEncodeMatchesMarshal(t *testing.T) {
+	records := []encoderTestStruct{{"hello", 123}, {"456", 45}}
+
+	var out strings.Builder
+
+	enc := positional_line.NewEncoder(&out)
+	for _, r := range records {
+		assert.Nil(t, enc.Encode(r))
+	}
+
+	expected, err := positional_line.Marshal(records)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, out.String())
+}
+
+func TestEncoderEncodeError(t *testing.T) {
+	type TestStruct struct {
+		Field1 string `positional:"abc"`
+	}
+
+	var out strings.Builder
+
+	err := positional_line.NewEncoder(&out).Encode(TestStruct{"hello"})
+
+	assert.ErrorIs(t, err, positional_line.ErrInvalidSize)
+	assert.Empty(t, out.String())
+}
+
+func TestEncoderSetCharset(t *testing.T) {
+	var out strings.Builder
+
+	enc := positional_line.NewEncoder(&out)
+	enc.SetCharset(charset.ISO88591)
+
+	assert.Nil(t, enc.Encode([]encoderTestStruct{{"Conceição", 1}, {"João", 2}}))
+	assert.Equal(t, "Concei\xe7\xe3o   100\nJo\xe3o        200", out.String())
+}
+
+func TestEncoderLineTerminators(t *testing.T) {
+	records := []encoderTestStruct{{"hello", 123}, {"456", 45}}
+
+	tests := []struct {
+		terminator string
+		final      bool
+		expected   string
+	}{
+		{"\n", false, "hello     12300\n456        4500"},
+		{"\r\n", false, "hello     12300\r\n456        4500"},
+		{"\r\n", true, "hello     12300\r\n456        4500\r\n"},
+		{"", false, "hello     12300456        4500"},
+		{"", true, "hello     12300456        4500"},
+	}
+
+	for _, test := range tests {
+		var out strings.Builder
+
+		enc := positional_line.NewEncoder(&out)
+		enc.SetLineTerminator(test.terminator)
+		enc.SetFinalTerminator(test.final)
+
+		for _, r := range records {
+			assert.Nil(t, enc.Encode(r))
+		}
+
+		assert.Equal(t, test.expected, out.String())
+	}
+}
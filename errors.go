@@ -0,0 +1,110 @@
+package positional_line
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidSize is raised when the size tag are not int
+	ErrInvalidSize = errors.New("posline: tag size should be an integer")
+	// ErrInvalidPosition is raised when the columns of a field overlap or leave a gap
+	ErrInvalidPosition = errors.New("posline: invalid field position")
+	// ErrInvalidTag is raised when a tag modifier has an invalid value
+	ErrInvalidTag = errors.New("posline: invalid tag modifier")
+	// ErrInvalidLineSize is raised when a line does not have the size of its record
+	ErrInvalidLineSize = errors.New("posline: invalid line size")
+	// ErrOverflow is raised when a value does not fit in its field
+	ErrOverflow = errors.New("posline: value overflows field")
+	// ErrInvalidSign is raised when a numeric field does not carry the expected sign
+	ErrInvalidSign = errors.New("posline: invalid sign")
+	// ErrControlCharacter is raised when a string field holds a control character
+	ErrControlCharacter = errors.New("posline: control character in field")
+	// ErrSplitCharacter is raised when a field boundary falls inside a UTF-8 character
+	ErrSplitCharacter = errors.New("posline: field boundary splits a UTF-8 character")
+	// ErrConstantMismatch is raised when a const field does not hold its value
+	ErrConstantMismatch = errors.New("posline: field does not hold its constant value")
+	// ErrSequence is raised when a seq field skips or repeats a number
+	ErrSequence = errors.New("posline: record out of sequence")
+	// ErrAggregate is raised when a trailer total does not match the records
+	ErrAggregate = errors.New("posline: total does not match the records")
+	// ErrBinaryWidth is raised when a comp3 or binary field is not decoded in byte widths
+	ErrBinaryWidth = errors.New("posline: comp3 and binary fields must be decoded with WidthBytes")
+	// ErrShortLine is the warning for a line shorter than its record
+	ErrShortLine = errors.New("posline: line shorter than record, padded with spaces")
+	// ErrLongLine is the warning for a line longer than its record
+	ErrLongLine = errors.New("posline: line longer than record, extra content ignored")
+	// ErrInvalidField is matched by every *FieldError
+	ErrInvalidField = errors.New("posline: invalid field")
+	// ErrUnknownRecord is raised when a line discriminator does not match any registered record
+	ErrUnknownRecord = errors.New("posline: unknown record type")
+	// ErrInvalidTarget is raised when the value to decode into is not a non-nil pointer
+	ErrInvalidTarget = errors.New("posline: v must be a non-nil pointer")
+	// ErrUnsupportedType is raised when the value to decode into is not a struct or a slice
+	ErrUnsupportedType = errors.New("posline: unsupported type")
+)
+
+// FieldError describes a field that could not be encoded or decoded
+type FieldError struct {
+	// Field is the name of the struct field
+	Field string
+	// Start and End are the 0-based offsets of the field in the line,
+	// counted in the width unit (runes by default), End being exclusive
+	Start int
+	End   int
+	// Value is the raw content of the field
+	Value string
+	// Err is the underlying cause
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field %s (columns %d-%d) %q: %v", e.Field, e.Start+1, e.End, e.Value, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes every FieldError match ErrInvalidField
+func (e *FieldError) Is(target error) bool {
+	return target == ErrInvalidField
+}
+
+// LineError associates an error with the 1-based line where it happened
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("posline: line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorList aggregates the failed lines of a decoding that collects errors
+type ErrorList []*LineError
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "posline: no errors"
+	case 1:
+		return l[0].Error()
+	}
+
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+func (l ErrorList) Unwrap() []error {
+	errs := make([]error, len(l))
+
+	for i, err := range l {
+		errs[i] = err
+	}
+
+	return errs
+}
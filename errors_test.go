@@ -0,0 +1,72 @@
+package positional_line_test
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vert-capital/positional_line"
+)
+
+type errorsTestStruct struct {
+	Field1 string `positional:"10"`
+	Field2 int    `positional:"5,leftpad"`
+}
+
+func TestUnmarshalFieldError(t *testing.T) {
+	var test []errorsTestStruct
+
+	err := positional_line.Unmarshal("hello     12345\nworld     12a45", &test)
+
+	var lineErr *positional_line.LineError
+	var fieldErr *positional_line.FieldError
+
+	assert.ErrorAs(t, err, &lineErr)
+	assert.Equal(t, 2, lineErr.Line)
+
+	assert.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "Field2", fieldErr.Field)
+	assert.Equal(t, 10, fieldErr.Start)
+	assert.Equal(t, 15, fieldErr.End)
+	assert.Equal(t, "12a45", fieldErr.Value)
+
+	assert.ErrorIs(t, err, positional_line.ErrInvalidField)
+	assert.ErrorIs(t, err, strconv.ErrSyntax)
+	assert.Equal(t, `posline: line 2: field Field2 (columns 11-15) "12a45": strconv.ParseInt: parsing "12a45": invalid syntax`, err.Error())
+}
+
+func TestUnmarshalLineSizeError(t *testing.T) {
+	var test []errorsTestStruct
+
+	err := positional_line.Unmarshal("hello     12345\nworld", &test)
+
+	var lineErr *positional_line.LineError
+
+	assert.ErrorAs(t, err, &lineErr)
+	assert.Equal(t, 2, lineErr.Line)
+	assert.ErrorIs(t, err, positional_line.ErrInvalidLineSize)
+	assert.False(t, errors.Is(err, positional_line.ErrInvalidField))
+}
+
+func TestDecoderLineError(t *testing.T) {
+	dec := positional_line.NewDecoder(strings.NewReader("hello     12345\nhello     12345\nhello     abcde"))
+
+	var test []errorsTestStruct
+
+	err := dec.Decode(&test)
+
+	var lineErr *positional_line.LineError
+
+	assert.ErrorAs(t, err, &lineErr)
+	assert.Equal(t, 3, lineErr.Line)
+	assert.ErrorIs(t, err, positional_line.ErrInvalidField)
+}
+
+func TestInvalidTargetError(t *testing.T) {
+	var test errorsTestStruct
+
+	assert.ErrorIs(t, positional_line.Unmarshal("hello     12345", test), positional_line.ErrInvalidTarget)
+	assert.ErrorIs(t, positional_line.Unmarshal("hello     12345", new(int)), positional_line.ErrUnsupportedType)
+}
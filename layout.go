@@ -0,0 +1,73 @@
+package positional_line
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Layout describes a file that mixes several record structs, such as the
+// header, detail and trailer lines of a CNAB file. Each line is dispatched to
+// a struct by the value found at a fixed discriminator position.
+type Layout struct {
+	start int
+	size  int
+	types map[string]reflect.Type
+}
+
+// NewLayout returns a layout whose discriminator starts at the 1-based column
+// start and is size characters wide
+func NewLayout(start, size int) *Layout {
+	return &Layout{
+		start: start,
+		size:  size,
+		types: make(map[string]reflect.Type),
+	}
+}
+
+// Register associates the discriminator value with the struct type of v
+func (l *Layout) Register(value string, v interface{}) error {
+	t := reflect.TypeOf(v)
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("posline: record %q must be a struct, got %v", value, t)
+	}
+
+	if len(value) != l.size {
+		return fmt.Errorf("posline: discriminator %q should have size %d", value, l.size)
+	}
+
+	// parsing the tags now reports them early and makes the totals of
+	// trailers known before the first record
+	if _, err := cachedSchema(t); err != nil {
+		return err
+	}
+
+	l.types[value] = t
+
+	return nil
+}
+
+// recordType returns the struct type registered for the discriminator of line
+func (l *Layout) recordType(line string, unit WidthUnit) (reflect.Type, error) {
+	start := l.start - 1
+	end := start + l.size
+
+	if start < 0 || end > unit.len(line) {
+		return nil, fmt.Errorf("%w: line too short for discriminator at column %d", ErrUnknownRecord, l.start)
+	}
+
+	var value string
+
+	if unit == WidthRunes {
+		value = string([]rune(line)[start:end])
+	} else {
+		value = line[start:end]
+	}
+
+	t, ok := l.types[value]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownRecord, value)
+	}
+
+	return t, nil
+}
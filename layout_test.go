@@ -0,0 +1,153 @@
+package positional_line_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vert-capital/positional_line"
+)
+
+type layoutHeader struct {
+	Type string `positional:"1"`
+	Name string `positional:"10"`
+}
+
+type layoutDetail struct {
+	Type   string  `positional:"1"`
+	Amount float64 `positional:"8,leftpad"`
+	Flag   bool    `positional:"2,leftpad"`
+}
+
+type layoutTrailer struct {
+	Type  string `positional:"1"`
+	Count int    `positional:"4,zerofill,leftpad"`
+}
+
+var layoutRecords = map[string]interface{}{
+	"0": layoutHeader{},
+	"1": layoutDetail{},
+	"9": layoutTrailer{},
+}
+
+// newTestLayout returns a layout discriminated by the first position, with
+// records registering the struct of each value
+func newTestLayout(t *testing.T, records map[string]interface{}) *positional_line.Layout {
+	layout := positional_line.NewLayout(1, 1)
+
+	for value, v := range records {
+		assert.Nil(t, layout.Register(value, v))
+	}
+
+	return layout
+}
+
+const layoutTestFile = "0REMESSA   \n1  123.45 1\n1    0.50 0\n90002"
+
+func TestLayoutRegisterError(t *testing.T) {
+	layout := positional_line.NewLayout(1, 1)
+
+	assert.NotNil(t, layout.Register("0", "not a struct"))
+	assert.NotNil(t, layout.Register("00", layoutHeader{}))
+}
+
+func TestDecoderDecodeWithLayout(t *testing.T) {
+	dec := positional_line.NewDecoder(strings.NewReader(layoutTestFile))
+	dec.UseLayout(newTestLayout(t, layoutRecords))
+
+	var records []interface{}
+
+	assert.Nil(t, dec.Decode(&records))
+	assert.Equal(t, []interface{}{
+		layoutHeader{"0", "REMESSA"},
+		layoutDetail{"1", 123.45, true},
+		layoutDetail{"1", 0.5, false},
+		layoutTrailer{"9", 2},
+	}, records)
+}
+
+func TestDecoderDecodeWithLayoutOneByOne(t *testing.T) {
+	dec := positional_line.NewDecoder(strings.NewReader(layoutTestFile))
+	dec.UseLayout(newTestLayout(t, layoutRecords))
+
+	var record interface{}
+
+	assert.Nil(t, dec.Decode(&record))
+	assert.Equal(t, layoutHeader{"0", "REMESSA"}, record)
+
+	assert.Nil(t, dec.Decode(&record))
+	assert.Equal(t, layoutDetail{"1", 123.45, true}, record)
+}
+
+func TestDecoderDecodeWithLayoutUnknownRecord(t *testing.T) {
+	dec := positional_line.NewDecoder(strings.NewReader("5unknown"))
+	dec.UseLayout(newTestLayout(t, layoutRecords))
+
+	var record interface{}
+
+	assert.ErrorIs(t, dec.Decode(&record), positional_line.ErrUnknownRecord)
+}
+
+func TestDecoderDecodeInterfaceWithoutLayout(t *testing.T) {
+	dec := positional_line.NewDecoder(strings.NewReader(layoutTestFile))
+
+	var record interface{}
+
+	assert.NotNil(t, dec.Decode(&record))
+}
+
+func TestEncoderEncodeHeterogeneousRecords(t *testing.T) {
+	var out strings.Builder
+
+	err := positional_line.NewEncoder(&out).Encode([]interface{}{
+		layoutHeader{"0", "REMESSA"},
+		layoutDetail{"1", 123.45, true},
+		layoutDetail{"1", 0.5, false},
+		layoutTrailer{"9", 2},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, layoutTestFile, out.String())
+}
+
+func TestDecoderFixedLengthWithLayout(t *testing.T) {
+	records := []interface{}{
+		layoutHeader{"0", "REMESSA"},
+		layoutDetail{"1", 123.45, true},
+		layoutDetail{"1", 0.5, false},
+		layoutTrailer{"9", 2},
+	}
+
+	var out strings.Builder
+
+	enc := positional_line.NewEncoder(&out)
+	enc.SetLineTerminator("")
+
+	assert.Nil(t, enc.Encode(records))
+	assert.Equal(t, "0REMESSA   1  123.45 11    0.50 090002", out.String())
+
+	dec := positional_line.NewDecoder(strings.NewReader(out.String()))
+	dec.UseLayout(newTestLayout(t, layoutRecords))
+	dec.UseFixedLength()
+
+	var decoded []interface{}
+
+	assert.Nil(t, dec.Decode(&decoded))
+	assert.Equal(t, records, decoded)
+}
+
+func TestDecoderFixedLengthUnknownRecord(t *testing.T) {
+	dec := positional_line.NewDecoder(strings.NewReader("0REMESSA   5  123.45 1"))
+	dec.UseLayout(newTestLayout(t, layoutRecords))
+	dec.UseFixedLength()
+
+	var decoded []interface{}
+
+	err := dec.Decode(&decoded)
+
+	var lineErr *positional_line.LineError
+
+	assert.ErrorIs(t, err, positional_line.ErrUnknownRecord)
+	assert.ErrorAs(t, err, &lineErr)
+	assert.Equal(t, 2, lineErr.Line)
+}
@@ -0,0 +1,82 @@
+package positional_line
+
+import (
+	"encoding"
+	"reflect"
+	"strings"
+)
+
+// PositionalMarshaler is implemented by types that write their own fixed
+// width representation. The returned content is padded to t.Size like any
+// other field.
+type PositionalMarshaler interface {
+	MarshalPositional(t Tag) (string, error)
+}
+
+// PositionalUnmarshaler is implemented by types that parse their own fixed
+// width representation. content is the raw field, padding included.
+type PositionalUnmarshaler interface {
+	UnmarshalPositional(t Tag, content string) error
+}
+
+var (
+	positionalMarshalerType   = reflect.TypeOf((*PositionalMarshaler)(nil)).Elem()
+	positionalUnmarshalerType = reflect.TypeOf((*PositionalUnmarshaler)(nil)).Elem()
+	textMarshalerType         = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType       = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// implements reports whether values of t, or their addresses, implement iface
+func implements(t reflect.Type, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+}
+
+// marshaler returns v as an implementation of iface, taking the address of
+// v (or of a copy) when the methods are declared on the pointer receiver
+func marshaler(v reflect.Value, iface reflect.Type) (interface{}, bool) {
+	if v.Type().Implements(iface) {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return nil, false
+		}
+
+		return v.Interface(), true
+	}
+
+	if !v.CanAddr() {
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		v = c
+	}
+
+	return v.Addr().Interface(), true
+}
+
+func encodePositionalMarshaler(v reflect.Value, t Tag) (string, error) {
+	m, ok := marshaler(v, positionalMarshalerType)
+
+	if !ok {
+		return "", nil
+	}
+
+	return m.(PositionalMarshaler).MarshalPositional(t)
+}
+
+func encodeTextMarshaler(v reflect.Value, t Tag) (string, error) {
+	m, ok := marshaler(v, textMarshalerType)
+
+	if !ok {
+		return "", nil
+	}
+
+	text, err := m.(encoding.TextMarshaler).MarshalText()
+
+	return string(text), err
+}
+
+func decodePositionalUnmarshaler(v reflect.Value, t Tag, content string) error {
+	return v.Addr().Interface().(PositionalUnmarshaler).UnmarshalPositional(t, content)
+}
+
+func decodeTextUnmarshaler(v reflect.Value, t Tag, content string) error {
+	return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(strings.TrimSpace(content)))
+}
@@ -0,0 +1,105 @@
+package positional_line_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vert-capital/positional_line"
+)
+
+// cpf keeps only the digits of a document and writes them zero padded
+type cpf string
+
+func (c cpf) MarshalPositional(t positional_line.Tag) (string, error) {
+	digits := strings.Map(func(r rune) rune {
+		if r < '0' || r > '9' {
+			return -1
+		}
+		return r
+	}, string(c))
+
+	if len(digits) != 11 {
+		return "", errors.New("invalid cpf")
+	}
+
+	return fmt.Sprintf("%0*s", t.Size, digits), nil
+}
+
+func (c *cpf) UnmarshalPositional(t positional_line.Tag, content string) error {
+	*c = cpf(fmt.Sprintf("%s.%s.%s-%s", content[3:6], content[6:9], content[9:12], content[12:14]))
+	return nil
+}
+
+type money struct {
+	Cents int64
+}
+
+func (m *money) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d,%02d", m.Cents/100, m.Cents%100)), nil
+}
+
+func (m *money) UnmarshalText(text []byte) error {
+	var units, cents int64
+
+	if _, err := fmt.Sscanf(string(text), "%d,%d", &units, &cents); err != nil {
+		return err
+	}
+
+	m.Cents = units*100 + cents
+
+	return nil
+}
+
+type marshalerTestStruct struct {
+	Document cpf   `positional:"14"`
+	Amount   money `positional:"10,leftpad"`
+}
+
+func TestMarshalCustomMarshalers(t *testing.T) {
+	result, err := positional_line.Marshal(marshalerTestStruct{"123.456.789-01", money{12345}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "00012345678901"+"    123,45", result)
+}
+
+func TestMarshalCustomMarshalerError(t *testing.T) {
+	_, err := positional_line.Marshal(marshalerTestStruct{"123", money{}})
+
+	var fieldErr *positional_line.FieldError
+
+	assert.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "Document", fieldErr.Field)
+	assert.EqualError(t, fieldErr.Err, "invalid cpf")
+}
+
+func TestUnmarshalCustomUnmarshalers(t *testing.T) {
+	var test marshalerTestStruct
+
+	err := positional_line.Unmarshal("00012345678901"+"    123,45", &test)
+
+	assert.Nil(t, err)
+	assert.Equal(t, marshalerTestStruct{"123.456.789-01", money{12345}}, test)
+}
+
+func TestPointerCustomMarshalers(t *testing.T) {
+	type record struct {
+		Document *cpf   `positional:"14"`
+		Amount   *money `positional:"10,leftpad"`
+		Missing  *cpf   `positional:"14"`
+	}
+
+	document := cpf("123.456.789-01")
+
+	result, err := positional_line.Marshal(record{Document: &document, Amount: &money{12345}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "00012345678901"+"    123,45"+"              ", result)
+
+	var test record
+
+	assert.Nil(t, positional_line.Unmarshal(result, &test))
+	assert.Equal(t, record{Document: &document, Amount: &money{12345}}, test)
+}
@@ -0,0 +1,223 @@
+package positional_line
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Sign tells where the sign of a numeric field is written
+type Sign int
+
+const (
+	// SignDefault writes "-" for negative numbers only, before the zeros of
+	// a zerofill,leftpad field or next to the digits otherwise
+	SignDefault Sign = iota
+	// SignLeading always writes "+" or "-" in the first position
+	SignLeading
+	// SignTrailing always writes "+" or "-" in the last position
+	SignTrailing
+	// SignCreditDebit writes "C" for credit (positive) or "D" for debit
+	// (negative) in the last position
+	SignCreditDebit
+	// SignOverpunch encodes the sign in the last digit, as COBOL zoned
+	// decimals do: "{" and "A" to "I" for +0 to +9, "}" and "J" to "R" for
+	// -0 to -9
+	SignOverpunch
+)
+
+var signNames = map[string]Sign{
+	"leading":   SignLeading,
+	"trailing":  SignTrailing,
+	"cd":        SignCreditDebit,
+	"overpunch": SignOverpunch,
+}
+
+const (
+	overpunchPositive = "{ABCDEFGHI"
+	overpunchNegative = "}JKLMNOPQR"
+)
+
+func parseSign(value string) (Sign, error) {
+	s, ok := signNames[value]
+
+	if !ok {
+		return SignDefault, fmt.Errorf("%w: sign %q", ErrInvalidTag, value)
+	}
+
+	return s, nil
+}
+
+// checkNumeric rejects sign and packed encoding modifiers that fields of
+// type ft and size t.Size could not be written with
+func checkNumeric(t Tag, ft reflect.Type) error {
+	if ft.Kind() == reflect.Ptr {
+		ft = ft.Elem()
+	}
+
+	switch t.Sign {
+	case SignLeading, SignTrailing, SignCreditDebit:
+		if t.Size < 2 {
+			return fmt.Errorf("%w: signed field should have room for the sign and a digit", ErrInvalidTag)
+		}
+	case SignOverpunch:
+		if t.Size < 1 {
+			return fmt.Errorf("%w: overpunched field should have at least 1 digit", ErrInvalidTag)
+		}
+	}
+
+	// custom marshalers get the tag and may use the modifiers themselves
+	plain := ft.Kind() == reflect.String || ft.Kind() == reflect.Bool || isTime(ft)
+	custom := !isTime(ft) && (implements(ft, positionalMarshalerType) || implements(ft, textMarshalerType))
+
+	if plain && !custom && (t.Sign != SignDefault || t.ImpliedDecimals) {
+		return fmt.Errorf("%w: sign, overpunch and decimals=N need a numeric field", ErrInvalidTag)
+	}
+
+	float := ft.Kind() == reflect.Float32 || ft.Kind() == reflect.Float64
+
+	switch t.Encoding {
+	case EncodingComp3:
+		if !isNumeric(ft) || float && !t.ImpliedDecimals {
+			return fmt.Errorf("%w: comp3 needs an integer or a float with decimals=N", ErrInvalidTag)
+		}
+	case EncodingBinary:
+		if !isNumeric(ft) || float {
+			return fmt.Errorf("%w: binary needs an integer", ErrInvalidTag)
+		}
+	}
+
+	return nil
+}
+
+// signParts splits the content of a numeric field into the sign written
+// before the padded digits, the digits and the sign written after them
+func (f *field) signParts(content string) (lead, digits, trail string) {
+	if !f.numeric {
+		return "", content, ""
+	}
+
+	negative := strings.HasPrefix(content, "-")
+	digits = strings.TrimPrefix(content, "-")
+
+	switch f.Sign {
+	case SignLeading:
+		lead = "+"
+		if negative {
+			lead = "-"
+		}
+	case SignTrailing:
+		trail = "+"
+		if negative {
+			trail = "-"
+		}
+	case SignCreditDebit:
+		trail = "C"
+		if negative {
+			trail = "D"
+		}
+	case SignOverpunch:
+		return "", overpunch(digits, negative), ""
+	default:
+		if !negative || !f.ZeroFill || !f.LeftPad {
+			return "", content, ""
+		}
+
+		lead = "-"
+	}
+
+	return lead, digits, trail
+}
+
+// overpunch replaces the last digit with the character carrying its sign
+func overpunch(digits string, negative bool) string {
+	if digits == "" || digits[len(digits)-1] < '0' || digits[len(digits)-1] > '9' {
+		return digits
+	}
+
+	table := overpunchPositive
+	if negative {
+		table = overpunchNegative
+	}
+
+	last := digits[len(digits)-1] - '0'
+
+	return digits[:len(digits)-1] + table[last:last+1]
+}
+
+// unoverpunch restores the last digit of an overpunched number, moving its
+// sign to the front
+func unoverpunch(s string) (string, error) {
+	last := s[len(s)-1:]
+
+	if i := strings.Index(overpunchPositive, last); i >= 0 {
+		return s[:len(s)-1] + strconv.Itoa(i), nil
+	}
+
+	if i := strings.Index(overpunchNegative, last); i >= 0 {
+		return "-" + s[:len(s)-1] + strconv.Itoa(i), nil
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrInvalidSign, last)
+}
+
+// unsign rewrites the content of a numeric field as a plain signed number
+func unsign(content string, t Tag) (string, error) {
+	s := strings.TrimSpace(content)
+
+	if t.Sign == SignDefault {
+		return s, nil
+	}
+
+	if s == "" {
+		return "", fmt.Errorf("%w: missing sign", ErrInvalidSign)
+	}
+
+	if t.Sign == SignOverpunch {
+		return unoverpunch(s)
+	}
+
+	var sign byte
+	var digits string
+
+	if t.Sign == SignLeading {
+		sign, digits = s[0], s[1:]
+	} else {
+		sign, digits = s[len(s)-1], s[:len(s)-1]
+	}
+
+	switch {
+	case t.Sign == SignCreditDebit && sign == 'C', t.Sign != SignCreditDebit && sign == '+':
+		return strings.TrimSpace(digits), nil
+	case t.Sign == SignCreditDebit && sign == 'D', t.Sign != SignCreditDebit && sign == '-':
+		return "-" + strings.TrimSpace(digits), nil
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrInvalidSign, sign)
+}
+
+// formatImplied formats f with the given number of implied decimals, leaving
+// out the decimal separator
+func formatImplied(f float64, decimals int) string {
+	return strings.Replace(strconv.FormatFloat(f, 'f', decimals, 64), ".", "", 1)
+}
+
+// parseImplied parses a number written with the given number of implied
+// decimals, inserting the decimal separator back before converting it
+func parseImplied(s string, decimals int) (float64, error) {
+	if decimals == 0 || s == "" {
+		return strconv.ParseFloat(s, 64)
+	}
+
+	var sign string
+	if s[0] == '-' || s[0] == '+' {
+		sign, s = s[:1], s[1:]
+	}
+
+	if len(s) <= decimals {
+		s = strings.Repeat("0", decimals-len(s)+1) + s
+	}
+
+	return strconv.ParseFloat(sign+s[:len(s)-decimals]+"."+s[len(s)-decimals:], 64)
+}
@@ -0,0 +1,160 @@
+package positional_line_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vert-capital/positional_line"
+)
+
+func TestSignedNumbers(t *testing.T) {
+	type TestStruct struct {
+		Default  int     `positional:"6,zerofill,leftpad"`
+		Spaces   int     `positional:"6,leftpad"`
+		Leading  int     `positional:"6,zerofill,leftpad,sign=leading"`
+		Trailing float64 `positional:"8,decimals=2,zerofill,leftpad,sign=trailing"`
+		Cd       int64   `positional:"6,zerofill,leftpad,sign=cd"`
+	}
+
+	tests := []struct {
+		input    TestStruct
+		expected string
+	}{
+		{
+			TestStruct{-12, -12, -12, -1.5, -12},
+			"-00012" + "   -12" + "-00012" + "0000150-" + "00012D",
+		},
+		{
+			TestStruct{12, 12, 12, 1.5, 12},
+			"000012" + "    12" + "+00012" + "0000150+" + "00012C",
+		},
+	}
+
+	for _, test := range tests {
+		result, err := positional_line.Marshal(test.input)
+
+		assert.Nil(t, err)
+		assert.Equal(t, test.expected, result)
+
+		var output TestStruct
+
+		assert.Nil(t, positional_line.Unmarshal(result, &output))
+		assert.Equal(t, test.input, output)
+	}
+}
+
+func TestSignedNumbersOverflow(t *testing.T) {
+	type TestStruct struct {
+		Amount int `positional:"4,zerofill,leftpad,sign=leading"`
+	}
+
+	result, err := positional_line.Marshal(TestStruct{-999})
+	assert.Nil(t, err)
+	assert.Equal(t, "-999", result)
+
+	_, err = positional_line.Marshal(TestStruct{1000})
+	assert.ErrorIs(t, err, positional_line.ErrOverflow)
+}
+
+func TestUnmarshalInvalidSign(t *testing.T) {
+	type TestStruct struct {
+		Amount int `positional:"6,zerofill,leftpad,sign=cd"`
+	}
+
+	var test TestStruct
+
+	err := positional_line.Unmarshal("00012X", &test)
+	assert.ErrorIs(t, err, positional_line.ErrInvalidSign)
+
+	err = positional_line.Unmarshal("      ", &test)
+	assert.ErrorIs(t, err, positional_line.ErrInvalidSign)
+}
+
+func TestSignInvalidTag(t *testing.T) {
+	type TestStruct struct {
+		Amount int `positional:"6,sign=middle"`
+	}
+
+	_, err := positional_line.Marshal(TestStruct{1})
+	assert.ErrorIs(t, err, positional_line.ErrInvalidTag)
+}
+
+func TestOverpunch(t *testing.T) {
+	type TestStruct struct {
+		Amount   float64 `positional:"7,decimals=2,zerofill,leftpad,overpunch"`
+		Quantity int     `positional:"4,zerofill,leftpad,overpunch"`
+		Units    uint    `positional:"3,zerofill,leftpad,sign=overpunch"`
+	}
+
+	tests := []struct {
+		input    TestStruct
+		expected string
+	}{
+		{TestStruct{123.45, 10, 7}, "001234E" + "001{" + "00G"},
+		{TestStruct{-123.41, -10, 0}, "001234J" + "001}" + "00{"},
+		{TestStruct{-0.09, -3, 9}, "000000R" + "000L" + "00I"},
+	}
+
+	for _, test := range tests {
+		result, err := positional_line.Marshal(test.input)
+
+		assert.Nil(t, err)
+		assert.Equal(t, test.expected, result)
+
+		var output TestStruct
+
+		assert.Nil(t, positional_line.Unmarshal(result, &output))
+		assert.Equal(t, test.input, output)
+	}
+}
+
+func TestUnmarshalInvalidOverpunch(t *testing.T) {
+	type TestStruct struct {
+		Quantity int `positional:"4,zerofill,leftpad,overpunch"`
+	}
+
+	var test TestStruct
+
+	assert.ErrorIs(t, positional_line.Unmarshal("0012", &test), positional_line.ErrInvalidSign)
+	assert.ErrorIs(t, positional_line.Unmarshal("    ", &test), positional_line.ErrInvalidSign)
+}
+
+func TestInvalidNumericModifiers(t *testing.T) {
+	type signTooSmall struct {
+		Amount int `positional:"1,sign=leading,overflow=truncate-right"`
+	}
+
+	type signEmpty struct {
+		Amount int `positional:"0,sign=trailing"`
+	}
+
+	type packedString struct {
+		Amount string `positional:"4,comp3"`
+	}
+
+	type packedFloat struct {
+		Amount float64 `positional:"4,comp3"`
+	}
+
+	type binaryFloat struct {
+		Amount float64 `positional:"4,binary,decimals=2"`
+	}
+
+	type signedString struct {
+		Amount string `positional:"4,sign=leading"`
+	}
+
+	type overpunchedBool struct {
+		Flag bool `positional:"1,overpunch"`
+	}
+
+	type decimalTime struct {
+		Date time.Time `positional:"8,decimals=2"`
+	}
+
+	for _, v := range []interface{}{signTooSmall{}, signEmpty{}, packedString{}, packedFloat{}, binaryFloat{}, signedString{}, overpunchedBool{}, decimalTime{}} {
+		_, err := positional_line.Marshal(v)
+		assert.ErrorIs(t, err, positional_line.ErrInvalidTag, "%T", v)
+	}
+}
@@ -0,0 +1,72 @@
+package positional_line
+
+import (
+	"fmt"
+)
+
+// Overflow tells what to do with content longer than its field
+type Overflow int
+
+const (
+	// OverflowDefault raises an error for numeric fields and truncates the
+	// right side of any other field
+	OverflowDefault Overflow = iota
+	// OverflowError raises ErrOverflow
+	OverflowError
+	// OverflowTruncateRight keeps the leftmost characters
+	OverflowTruncateRight
+	// OverflowTruncateLeft keeps the rightmost characters
+	OverflowTruncateLeft
+)
+
+var overflowNames = map[string]Overflow{
+	"error":          OverflowError,
+	"truncate-right": OverflowTruncateRight,
+	"truncate-left":  OverflowTruncateLeft,
+}
+
+func parseOverflow(value string) (Overflow, error) {
+	o, ok := overflowNames[value]
+
+	if !ok {
+		return OverflowDefault, fmt.Errorf("%w: overflow %q", ErrInvalidTag, value)
+	}
+
+	return o, nil
+}
+
+// overflow returns the policy of f, falling back to the global policy and
+// then to the default of its kind
+func (f *field) overflow(global Overflow) Overflow {
+	if f.Overflow != OverflowDefault {
+		return f.Overflow
+	}
+
+	if global != OverflowDefault {
+		return global
+	}
+
+	if f.numeric {
+		return OverflowError
+	}
+
+	return OverflowTruncateRight
+}
+
+// fit applies the overflow policy to content longer than size
+func (f *field) fit(content string, size int, o *encodeOptions) (string, error) {
+	width := o.unit.len(content)
+
+	if width <= size {
+		return content, nil
+	}
+
+	switch f.overflow(o.overflow) {
+	case OverflowError:
+		return "", fmt.Errorf("%w: width %d in a field of size %d", ErrOverflow, width, size)
+	case OverflowTruncateLeft:
+		return o.unit.keepRight(content, size), nil
+	}
+
+	return o.unit.keepLeft(content, size), nil
+}
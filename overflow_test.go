@@ -0,0 +1,91 @@
+package positional_line_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vert-capital/positional_line"
+)
+
+func TestMarshalNumericOverflow(t *testing.T) {
+	type TestStruct struct {
+		Name   string  `positional:"5"`
+		Amount float64 `positional:"11,decimals=2,zerofill,leftpad"`
+	}
+
+	_, err := positional_line.Marshal(TestStruct{"hello", 12345678901.23})
+
+	var fieldErr *positional_line.FieldError
+
+	assert.ErrorIs(t, err, positional_line.ErrOverflow)
+	assert.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "Amount", fieldErr.Field)
+	assert.Equal(t, "1234567890123", fieldErr.Value)
+}
+
+func TestMarshalStringTruncatedByDefault(t *testing.T) {
+	type TestStruct struct {
+		Name string `positional:"5"`
+	}
+
+	result, err := positional_line.Marshal(TestStruct{"hello world"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", result)
+}
+
+func TestMarshalOverflowTag(t *testing.T) {
+	type TestStruct struct {
+		Name    string `positional:"5,overflow=error"`
+		Account int    `positional:"4,overflow=truncate-left"`
+		Code    int    `positional:"4,overflow=truncate-right"`
+	}
+
+	_, err := positional_line.Marshal(TestStruct{"hello world", 1, 1})
+	assert.ErrorIs(t, err, positional_line.ErrOverflow)
+
+	result, err := positional_line.Marshal(TestStruct{"hello", 123456, 123456})
+	assert.Nil(t, err)
+	assert.Equal(t, "hello34561234", result)
+}
+
+func TestEncoderSetOverflow(t *testing.T) {
+	type TestStruct struct {
+		Name    string `positional:"5"`
+		Account int    `positional:"4"`
+		Code    int    `positional:"4,overflow=error"`
+	}
+
+	var out strings.Builder
+
+	enc := positional_line.NewEncoder(&out)
+	enc.SetOverflow(positional_line.OverflowTruncateLeft)
+
+	assert.Nil(t, enc.Encode(TestStruct{"hello world", 123456, 1234}))
+	assert.Equal(t, "world34561234", out.String())
+
+	assert.ErrorIs(t, enc.Encode(TestStruct{"hello", 1, 12345}), positional_line.ErrOverflow)
+}
+
+func TestParseValueOverflow(t *testing.T) {
+	type TestStruct struct {
+		Amount int `positional:"3"`
+	}
+
+	tags, err := positional_line.ParseTags(reflect.TypeOf(TestStruct{}))
+	assert.Nil(t, err)
+
+	_, err = positional_line.ParseValue(reflect.ValueOf(TestStruct{1234}), tags)
+	assert.ErrorIs(t, err, positional_line.ErrOverflow)
+}
+
+func TestOverflowInvalidTag(t *testing.T) {
+	type TestStruct struct {
+		Amount int `positional:"3,overflow=drop"`
+	}
+
+	_, err := positional_line.Marshal(TestStruct{1})
+	assert.ErrorIs(t, err, positional_line.ErrInvalidTag)
+}
@@ -3,6 +3,7 @@ package pad
 import (
 	"errors"
 	"strings"
+	"unicode/utf8"
 )
 
 // Right é uma função que preenche uma string à direita com um caractere de preenchimento especificado até atingir um tamanho desejado.
@@ -52,3 +53,64 @@ func Left(str string, size int, pad string) (string, error) {
 	// Retorna a string de entrada, preenchida à esquerda com o caractere de preenchimento até atingir o tamanho desejado.
 	return strings.Repeat(pad, size-len(content)) + string(content), nil
 }
+
+// RightBytes é como Right, mas mede o tamanho em bytes, sem nunca cortar um caractere UTF-8 ao meio.
+func RightBytes(str string, size int, pad string) (string, error) {
+	content, fill, err := bytesFill(str, size, pad)
+
+	if err != nil {
+		return "", err
+	}
+
+	// Retorna a string de entrada, preenchida à direita até atingir o tamanho desejado em bytes.
+	return content + fill, nil
+}
+
+// LeftBytes é como Left, mas mede o tamanho em bytes, sem nunca cortar um caractere UTF-8 ao meio.
+func LeftBytes(str string, size int, pad string) (string, error) {
+	content, fill, err := bytesFill(str, size, pad)
+
+	if err != nil {
+		return "", err
+	}
+
+	// Retorna a string de entrada, preenchida à esquerda até atingir o tamanho desejado em bytes.
+	return fill + content, nil
+}
+
+// CutBytes corta a string para no máximo size bytes, recuando até o início do último caractere UTF-8 que cabe.
+func CutBytes(str string, size int) string {
+	if len(str) <= size {
+		return str
+	}
+
+	// Recua enquanto o byte do corte for a continuação de um caractere.
+	for size > 0 && !utf8.RuneStart(str[size]) {
+		size--
+	}
+
+	return str[:size]
+}
+
+// bytesFill corta a string para o tamanho em bytes e retorna o preenchimento que completa esse tamanho.
+func bytesFill(str string, size int, pad string) (string, string, error) {
+	// Verifica se o tamanho desejado é negativo. Se for, retorna um erro.
+	if size < 0 {
+		return "", "", errors.New("size must be non-negative")
+	}
+
+	// Verifica se a string de preenchimento está vazia. Se estiver, retorna um erro.
+	if pad == "" {
+		return "", "", errors.New("pad must not be empty")
+	}
+
+	content := CutBytes(str, size)
+	missing := size - len(content)
+
+	// Verifica se o preenchimento consegue completar exatamente o tamanho desejado.
+	if missing%len(pad) != 0 {
+		return "", "", errors.New("pad does not fit the remaining size")
+	}
+
+	return content, strings.Repeat(pad, missing/len(pad)), nil
+}
@@ -61,3 +61,58 @@ func TestRight(t *testing.T) {
 		}
 	}
 }
+
+func TestLeftBytes(t *testing.T) {
+	tests := []struct {
+		str         string
+		size        int
+		pad         string
+		expected    string
+		expectedErr bool
+	}{
+		{"João", 6, " ", " João", false},
+		{"João", 5, " ", "João", false},
+		{"João", 3, " ", " Jo", false},
+		{"ã", 1, "0", "0", false},
+		{"João", 6, "·", "", true},
+		{"teste error", -1, "*", "", true},
+		{"teste error empty", 1, "", "", true},
+	}
+
+	for _, test := range tests {
+		result, err := pad.LeftBytes(test.str, test.size, test.pad)
+		if result != test.expected {
+			t.Errorf("LeftBytes(%q, %d, %q) = %q; want %q", test.str, test.size, test.pad, result, test.expected)
+		}
+		if (err != nil) != test.expectedErr {
+			t.Errorf("LeftBytes(%q, %d, %q) returned error %v; want error %t", test.str, test.size, test.pad, err, test.expectedErr)
+		}
+	}
+}
+
+func TestRightBytes(t *testing.T) {
+	tests := []struct {
+		str         string
+		size        int
+		pad         string
+		expected    string
+		expectedErr bool
+	}{
+		{"João", 6, " ", "João ", false},
+		{"João", 5, " ", "João", false},
+		{"João", 3, " ", "Jo ", false},
+		{"ã", 1, "0", "0", false},
+		{"teste error", -1, "*", "", true},
+		{"teste error empty", 1, "", "", true},
+	}
+
+	for _, test := range tests {
+		result, err := pad.RightBytes(test.str, test.size, test.pad)
+		if result != test.expected {
+			t.Errorf("RightBytes(%q, %d, %q) = %q; want %q", test.str, test.size, test.pad, result, test.expected)
+		}
+		if (err != nil) != test.expectedErr {
+			t.Errorf("RightBytes(%q, %d, %q) returned error %v; want error %t", test.str, test.size, test.pad, err, test.expectedErr)
+		}
+	}
+}
@@ -5,58 +5,195 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
-
-	"github.com/vert-capital/positional_line/pad"
+	"time"
+	"unicode/utf8"
 )
 
 func ParseTags(t reflect.Type) (TagCollection, error) {
 	var tags []Tag
 
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
+	// next is the 1-based column right after the last parsed field
+	next := 1
 
-		ftag := field.Tag.Get(tagName)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
 
-		if ftag == "" {
-			continue
-		}
+	for _, field := range recordFields(t) {
+		ftag := field.Tag.Get(tagName)
 
 		opts := strings.Split(ftag, ",")
 
-		size, err := strconv.Atoi(opts[0])
+		start, size, err := parsePosition(opts[0])
 
 		if err != nil {
-			return TagCollection{}, ErrInvalidSize
+			return TagCollection{}, fmt.Errorf("%w for field %s", err, field.Name)
 		}
 
-		zerofill := false
-		leftpad := false
-		nofloat := false
+		t := Tag{
+			Name:   field.name,
+			Start:  start,
+			Size:   size,
+			Filler: field.StructField.Name == "_",
+		}
 
 		modifiers := opts[1:]
 
 		for _, m := range modifiers {
-			if m == "zerofill" {
-				zerofill = true
+			key, value, _ := strings.Cut(m, "=")
+
+			switch key {
+			case "zerofill":
+				t.ZeroFill = true
+			case "gap":
+				t.Gap = true
+			case "seq":
+				switch field.Type.Kind() {
+				case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+					reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				default:
+					return TagCollection{}, fmt.Errorf("%w: seq field %s should be an integer", ErrInvalidTag, field.Name)
+				}
+
+				t.Seq = true
+			case "count":
+				t.Aggregate.Kind = AggregateCount
+				t.Aggregate.Record = value
+			case "sum":
+				sum, err := parseSum(value)
+
+				if err != nil {
+					return TagCollection{}, fmt.Errorf("%w for field %s", err, field.Name)
+				}
+
+				t.Aggregate.Kind = sum.Kind
+				t.Aggregate.Record = sum.Record
+				t.Aggregate.Field = sum.Field
+			case "scope":
+				scope, err := parseScope(value)
+
+				if err != nil {
+					return TagCollection{}, fmt.Errorf("%w for field %s", err, field.Name)
+				}
+
+				t.Aggregate.Scope = scope
+			case "filler":
+				t.Filler = true
+			case "const":
+				if value == "" {
+					return TagCollection{}, fmt.Errorf("%w: empty const for field %s", ErrInvalidTag, field.Name)
+				}
+
+				t.Const = value
+			case "leftpad":
+				t.LeftPad = true
+			case "nofloat":
+				t.NoFloat = true
+			case "decimals":
+				decimals, err := strconv.Atoi(value)
+
+				if err != nil || decimals < 0 {
+					return TagCollection{}, fmt.Errorf("%w: decimals %q for field %s", ErrInvalidTag, value, field.Name)
+				}
+
+				t.ImpliedDecimals = true
+				t.Decimals = decimals
+			case "pad":
+				if utf8.RuneCountInString(value) != 1 {
+					return TagCollection{}, fmt.Errorf("%w: pad %q for field %s should be a single character", ErrInvalidTag, value, field.Name)
+				}
+
+				switch value {
+				case "0":
+					t.ZeroFill = true
+				case " ":
+				default:
+					t.Pad = value
+				}
+			case "upper":
+				t.Normalize |= NormalizeUpper
+			case "ascii":
+				t.Normalize |= NormalizeASCII
+			case "control":
+				control, err := parseControl(value)
+
+				if err != nil {
+					return TagCollection{}, fmt.Errorf("%w for field %s", err, field.Name)
+				}
+
+				t.Control = control
+			case "overflow":
+				overflow, err := parseOverflow(value)
+
+				if err != nil {
+					return TagCollection{}, fmt.Errorf("%w for field %s", err, field.Name)
+				}
+
+				t.Overflow = overflow
+			case "comp3":
+				if size < 1 {
+					return TagCollection{}, fmt.Errorf("%w: packed field %s should have at least 1 byte", ErrInvalidTag, field.Name)
+				}
+
+				t.Encoding = EncodingComp3
+			case "binary":
+				if size < 1 || size > 8 {
+					return TagCollection{}, fmt.Errorf("%w: binary field %s should have 1 to 8 bytes", ErrInvalidTag, field.Name)
+				}
+
+				t.Encoding = EncodingBinary
+			case "overpunch":
+				t.Sign = SignOverpunch
+			case "sign":
+				sign, err := parseSign(value)
+
+				if err != nil {
+					return TagCollection{}, fmt.Errorf("%w for field %s", err, field.Name)
+				}
+
+				t.Sign = sign
+			case "date":
+				t.DateLayout = value
+			case "zerodate":
+				zero, err := parseZeroDate(value)
+
+				if err != nil {
+					return TagCollection{}, fmt.Errorf("%w for field %s", err, field.Name)
+				}
+
+				t.ZeroDate = zero
+			case "tz":
+				loc, err := time.LoadLocation(value)
+
+				if err != nil {
+					return TagCollection{}, fmt.Errorf("%w: timezone %q for field %s: %v", ErrInvalidTag, value, field.Name, err)
+				}
+
+				t.Location = loc
 			}
+		}
 
-			if m == "leftpad" {
-				leftpad = true
-			}
+		if err := checkNumeric(t, field.Type); err != nil {
+			return TagCollection{}, fmt.Errorf("%w for field %s", err, field.Name)
+		}
 
-			if m == "nofloat" {
-				nofloat = true
-			}
+		if t.Aggregate.Kind != AggregateNone && !isNumeric(field.Type) {
+			return TagCollection{}, fmt.Errorf("%w: total field %s should be a number", ErrInvalidTag, field.Name)
 		}
 
-		t := Tag{
-			Name:     field.Name,
-			Size:     size,
-			LeftPad:  leftpad,
-			ZeroFill: zerofill,
-			NoFloat:  nofloat,
+		if t.Start > 0 {
+			switch {
+			case t.Start < next:
+				return TagCollection{}, fmt.Errorf("%w: field %s starts at column %d, overlapping the previous field that ends at column %d", ErrInvalidPosition, field.Name, t.Start, next-1)
+			case t.Start > next && !t.Gap:
+				return TagCollection{}, fmt.Errorf("%w: field %s starts at column %d, leaving columns %d-%d unused", ErrInvalidPosition, field.Name, t.Start, next, t.Start-1)
+			}
+
+			next = t.Start
 		}
 
+		next += t.Size
+
 		tags = append(tags, t)
 	}
 
@@ -68,167 +205,240 @@ func ParseTags(t reflect.Type) (TagCollection, error) {
 	return line, nil
 }
 
-func UnparseValue(rv reflect.Value, line TagCollection, content string) error {
-	var err error
-	t := rv.Type()
-	start := 0
+// recordField is a tagged field of a record struct, possibly inside nested
+// or embedded structs
+type recordField struct {
+	reflect.StructField
+
+	// name is the path of the field from the record, such as Address.City;
+	// fields of embedded structs are promoted and keep their own name
+	name string
+	// index is the sequence of field indexes that leads to the field
+	index []int
+}
+
+// recordFields returns the tagged fields of t in the order they are written,
+// flattening the fields of untagged struct fields, and pointers to structs,
+// in place
+func recordFields(t reflect.Type) []recordField {
+	return appendRecordFields(nil, t, "", nil, map[reflect.Type]bool{t: true})
+}
 
-	spectedLineSize := 0
+func appendRecordFields(fields []recordField, t reflect.Type, prefix string, index []int, visiting map[reflect.Type]bool) []recordField {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		path := append(append([]int(nil), index...), i)
 
-	for i := 0; i < rv.NumField(); i++ {
-		field := t.Field(i)
+		if sf.Tag.Get(tagName) != "" {
+			fields = append(fields, recordField{StructField: sf, name: prefix + sf.Name, index: path})
+			continue
+		}
 
-		tg := Tags(line)[field.Name]
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
 
-		if tg == (Tag{}) {
+		if ft.Kind() != reflect.Struct || isTime(ft) || visiting[ft] || !sf.IsExported() && !sf.Anonymous {
 			continue
 		}
 
-		spectedLineSize += tg.Size
-	}
+		nested := prefix
+		if !sf.Anonymous {
+			nested += sf.Name + "."
+		}
 
-	if len(content) != spectedLineSize {
-		ErrInvalidLineSize := fmt.Errorf("Invalid line size. Expected %d, got %d line \n %s", spectedLineSize, len(content), content)
-		return ErrInvalidLineSize
+		visiting[ft] = true
+		fields = appendRecordFields(fields, ft, nested, path, visiting)
+		delete(visiting, ft)
 	}
 
-	for i := 0; i < rv.NumField(); i++ {
-		field := t.Field(i)
-		value := rv.Field(i)
+	return fields
+}
 
-		tg := Tags(line)[field.Name]
+// parsePosition parses the first option of a tag: either the size of the
+// field, placed right after the previous one, or its 1-based inclusive
+// columns as start-end
+func parsePosition(opt string) (start, size int, err error) {
+	from, to, ok := strings.Cut(opt, "-")
 
-		if tg == (Tag{}) {
-			continue
+	if !ok {
+		size, err = strconv.Atoi(opt)
+
+		if err != nil {
+			return 0, 0, ErrInvalidSize
 		}
 
-		end := start + tg.Size
+		return 0, size, nil
+	}
 
-		fieldContent := content[start:end]
+	start, err = strconv.Atoi(from)
 
-		err = Unconvert(value, tg, fieldContent)
+	if err != nil {
+		return 0, 0, ErrInvalidSize
+	}
 
-		if err != nil {
-			return err
-		}
+	end, err := strconv.Atoi(to)
 
-		start = end
+	if err != nil {
+		return 0, 0, ErrInvalidSize
 	}
 
-	return nil
+	if start < 1 || end < start {
+		return 0, 0, fmt.Errorf("%w: columns %q", ErrInvalidPosition, opt)
+	}
+
+	return start, end - start + 1, nil
+}
+
+func UnparseValue(rv reflect.Value, line TagCollection, content string) error {
+	s, err := compileSchema(rv.Type(), line)
+
+	if err != nil {
+		return err
+	}
+
+	return s.decode(rv, content, &decodeOptions{})
 }
 
 func Unconvert(v reflect.Value, t Tag, content string) error {
-	switch v.Kind() {
-	case reflect.String:
-		v.SetString(strings.TrimSpace(content))
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		i, err := strconv.ParseInt(strings.TrimSpace(content), 10, 64)
+	return decoderFor(v.Type())(v, t, content)
+}
 
-		if err != nil {
-			return err
-		}
+func ParseValue(rv reflect.Value, line TagCollection) (string, error) {
+	s, err := compileSchema(rv.Type(), line)
 
-		v.SetInt(i)
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		i, err := strconv.ParseUint(strings.TrimSpace(content), 10, 64)
+	if err != nil {
+		return "", err
+	}
 
-		if err != nil {
-			return err
-		}
+	return s.encode(rv, &encodeOptions{})
+}
+
+// Convert returns the unpadded content of v. Errors raised by custom
+// marshalers are discarded; ParseValue reports them.
+func Convert(v reflect.Value, t Tag) string {
+	content, _ := encoderFor(v.Type())(v, t)
+	return content
+}
 
-		v.SetUint(i)
-	case reflect.Float32, reflect.Float64:
-		f, err := strconv.ParseFloat(strings.TrimSpace(content), 64)
+func encodeString(v reflect.Value, t Tag) (string, error) {
+	return sanitize(normalize(v.String(), t.Normalize), t.Control)
+}
 
-		if err != nil {
-			return err
-		}
+func encodeInt(v reflect.Value, t Tag) (string, error) {
+	return strconv.FormatInt(v.Int(), 10), nil
+}
+
+func encodeUint(v reflect.Value, t Tag) (string, error) {
+	return strconv.FormatUint(v.Uint(), 10), nil
+}
 
-		v.SetFloat(f)
-	case reflect.Bool:
-		b, err := strconv.ParseBool(strings.TrimSpace(content))
+func encodeFloat(v reflect.Value, t Tag) (string, error) {
+	if t.ImpliedDecimals {
+		return formatImplied(v.Float(), t.Decimals), nil
+	}
 
-		if err != nil {
-			return err
-		}
+	value := fmt.Sprintf("%.2f", v.Float())
+
+	if t.NoFloat {
+		return strings.Replace(value, ".", "", 1), nil
+	}
+
+	return value, nil
+}
 
-		v.SetBool(b)
+func encodeBool(v reflect.Value, t Tag) (string, error) {
+	if v.Bool() {
+		return "1", nil
 	}
 
+	return "0", nil
+}
+
+func encodeNothing(v reflect.Value, t Tag) (string, error) {
+	return "", nil
+}
+
+func decodeString(v reflect.Value, t Tag, content string) error {
+	v.SetString(strings.TrimSpace(content))
 	return nil
 }
 
-func ParseValue(rv reflect.Value, line TagCollection) (string, error) {
-	var err error
-	var content strings.Builder
-	t := rv.Type()
+func decodeInt(v reflect.Value, t Tag, content string) error {
+	s, err := unsign(content, t)
 
-	for i := 0; i < rv.NumField(); i++ {
-		field := t.Field(i)
-		value := rv.Field(i)
+	if err != nil {
+		return err
+	}
 
-		tg := Tags(line)[field.Name]
+	i, err := strconv.ParseInt(s, 10, 64)
 
-		if tg == (Tag{}) {
-			continue
-		}
+	if err != nil {
+		return err
+	}
 
-		fieldContent := Convert(value, tg)
+	v.SetInt(i)
 
-		var sep string
-		if tg.ZeroFill {
-			sep = "0"
-		} else {
-			sep = " "
-		}
+	return nil
+}
 
-		var fline string
-		if tg.LeftPad {
-			fline, err = pad.Left(fieldContent, tg.Size, sep)
-		} else {
-			fline, err = pad.Right(fieldContent, tg.Size, sep)
-		}
+func decodeUint(v reflect.Value, t Tag, content string) error {
+	s, err := unsign(content, t)
 
-		if err != nil {
-			return "", err
-		}
+	if err != nil {
+		return err
+	}
+
+	i, err := strconv.ParseUint(s, 10, 64)
 
-		content.WriteString(fline)
+	if err != nil {
+		return err
 	}
 
-	return content.String(), nil
+	v.SetUint(i)
+
+	return nil
 }
 
-func Convert(v reflect.Value, t Tag) string {
-	var content string
-
-	switch v.Kind() {
-	case reflect.String:
-		content = v.Interface().(string)
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		content = strconv.FormatInt(v.Int(), 10)
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		content = strconv.FormatUint(v.Uint(), 10)
-	case reflect.Float32, reflect.Float64:
-		value := fmt.Sprintf("%.2f", v.Float())
-
-		if t.NoFloat {
-			content = strings.Replace(value, ".", "", 1)
-		} else {
-			content = value
-		}
-	case reflect.Bool:
-		value := v.Interface().(bool)
+func decodeFloat(v reflect.Value, t Tag, content string) error {
+	s, err := unsign(content, t)
 
-		if value {
-			content = "1"
-		} else {
-			content = "0"
-		}
+	if err != nil {
+		return err
 	}
 
-	return content
+	var f float64
+
+	if t.ImpliedDecimals {
+		f, err = parseImplied(s, t.Decimals)
+	} else {
+		f, err = strconv.ParseFloat(s, 64)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	v.SetFloat(f)
+
+	return nil
+}
+
+func decodeBool(v reflect.Value, t Tag, content string) error {
+	b, err := strconv.ParseBool(strings.TrimSpace(content))
+
+	if err != nil {
+		return err
+	}
+
+	v.SetBool(b)
+
+	return nil
+}
+
+func decodeNothing(v reflect.Value, t Tag, content string) error {
+	return nil
 }
 
 func Tags(l TagCollection) map[string]Tag {
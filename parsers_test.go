@@ -311,3 +311,128 @@ func TestTags(t *testing.T) {
 		}
 	}
 }
+
+func TestParseValueCustomPad(t *testing.T) {
+	type TestStruct struct {
+		Amount   float64 `positional:"10,decimals=2,leftpad,pad=*"`
+		Reserved string  `positional:"6,pad=X"`
+		Code     int     `positional:"4,leftpad,pad=0"`
+		Name     string  `positional:"6,pad=·"`
+	}
+
+	tags, err := positional_line.ParseTags(reflect.TypeOf(TestStruct{}))
+	assert.Nil(t, err)
+
+	assert.Equal(t, "*", tags.Tags[0].Pad)
+	assert.True(t, tags.Tags[2].ZeroFill)
+	assert.Equal(t, "", tags.Tags[2].Pad)
+
+	input := TestStruct{-123.45, "", 7, "ab"}
+
+	result, err := positional_line.ParseValue(reflect.ValueOf(input), tags)
+	assert.Nil(t, err)
+	assert.Equal(t, "****-12345"+"XXXXXX"+"0007"+"ab····", result)
+
+	var output TestStruct
+
+	assert.Nil(t, positional_line.UnparseValue(reflect.ValueOf(&output).Elem(), tags, result))
+	assert.Equal(t, input, output)
+}
+
+func TestUnconvertCustomPad(t *testing.T) {
+	var value string
+
+	err := positional_line.Unconvert(reflect.ValueOf(&value).Elem(), positional_line.Tag{Pad: "*", LeftPad: true}, "***a*b")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "a*b", value)
+}
+
+func TestParseTagsInvalidPad(t *testing.T) {
+	type TestStruct struct {
+		Name string `positional:"6,pad=ab"`
+	}
+
+	_, err := positional_line.ParseTags(reflect.TypeOf(TestStruct{}))
+	assert.ErrorIs(t, err, positional_line.ErrInvalidTag)
+}
+
+func TestParseTagsExplicitPositions(t *testing.T) {
+	type TestStruct struct {
+		Bank    string `positional:"1-3"`
+		Lot     int    `positional:"4,zerofill,leftpad"`
+		Agency  string `positional:"8-12"`
+		Account string `positional:"18-30,gap"`
+	}
+
+	tags, err := positional_line.ParseTags(reflect.TypeOf(TestStruct{}))
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, tags.Tags[0].Start)
+	assert.Equal(t, 3, tags.Tags[0].Size)
+	assert.Equal(t, 0, tags.Tags[1].Start)
+	assert.Equal(t, 13, tags.Tags[3].Size)
+
+	input := TestStruct{"341", 7, "1234", "987654"}
+
+	result, err := positional_line.ParseValue(reflect.ValueOf(input), tags)
+	assert.Nil(t, err)
+	assert.Equal(t, "341"+"0007"+"1234 "+"     "+"987654       ", result)
+
+	var output TestStruct
+
+	assert.Nil(t, positional_line.UnparseValue(reflect.ValueOf(&output).Elem(), tags, result))
+	assert.Equal(t, input, output)
+}
+
+func TestParseTagsInvalidPositions(t *testing.T) {
+	type Overlap struct {
+		Bank   string `positional:"1-3"`
+		Agency string `positional:"3-7"`
+	}
+
+	type Gap struct {
+		Bank   string `positional:"1-3"`
+		Agency string `positional:"5-7"`
+	}
+
+	type Reversed struct {
+		Bank string `positional:"5-1"`
+	}
+
+	type Malformed struct {
+		Bank string `positional:"1-x"`
+	}
+
+	_, err := positional_line.ParseTags(reflect.TypeOf(Overlap{}))
+	assert.ErrorIs(t, err, positional_line.ErrInvalidPosition)
+
+	_, err = positional_line.ParseTags(reflect.TypeOf(Gap{}))
+	assert.ErrorIs(t, err, positional_line.ErrInvalidPosition)
+
+	_, err = positional_line.ParseTags(reflect.TypeOf(Reversed{}))
+	assert.ErrorIs(t, err, positional_line.ErrInvalidPosition)
+
+	_, err = positional_line.ParseTags(reflect.TypeOf(Malformed{}))
+	assert.ErrorIs(t, err, positional_line.ErrInvalidSize)
+}
+
+func TestCustomPadWithSign(t *testing.T) {
+	type TestStruct struct {
+		Leading  int     `positional:"6,pad=*,leftpad,sign=leading"`
+		Trailing int     `positional:"6,pad=*,leftpad,sign=trailing"`
+		Credit   float64 `positional:"6,pad=*,decimals=2,sign=cd"`
+	}
+
+	input := TestStruct{-12, 34, -0.5}
+
+	result, err := positional_line.Marshal(input)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "-***12"+"***34+"+"050**D", result)
+
+	var output TestStruct
+
+	assert.Nil(t, positional_line.Unmarshal(result, &output))
+	assert.Equal(t, input, output)
+}
@@ -4,14 +4,13 @@ import (
 	"errors"
 	"reflect"
 	"strings"
+	"time"
 )
 
 const tagName string = "positional"
 
-var (
-	// ErrInvalidSize is raised when the size tag are not int
-	ErrInvalidSize = errors.New("posline: tag size should be an integer")
-)
+// defaultDateLayout is the DDMMYYYY layout used when a date field has no layout
+const defaultDateLayout string = "02012006"
 
 type TagCollection struct {
 	Name string
@@ -19,105 +18,173 @@ type TagCollection struct {
 }
 
 type Tag struct {
-	Name     string
+	Name string
+
+	// Start is the 1-based column of a field tagged as start-end; zero
+	// places the field right after the previous one. Gap allows the columns
+	// before Start to be left unused.
+	Start int
+	Gap   bool
+
+	// Filler fields, named _ or tagged filler, are written with their pad
+	// character and skipped when parsing. Const is the value set by
+	// const=VALUE, always written and checked when parsing.
+	Filler bool
+	Const  string
+
+	// Seq fields are numbered by the Encoder with the position of the record
+	// and checked by the Decoder
+	Seq bool
+	// Aggregate is the total of the records held by a trailer field
+	Aggregate Aggregate
+
 	Size     int
 	LeftPad  bool
 	ZeroFill bool
 	NoFloat  bool
+
+	// Pad is the character set by pad=<char> to fill the field instead of
+	// spaces, or zeros with zerofill
+	Pad string
+
+	// ImpliedDecimals is set by the decimals=N modifier: floats are written
+	// with Decimals implied digits and no separator, and divided back when
+	// parsed. Integer fields are taken as already holding minor units.
+	ImpliedDecimals bool
+	Decimals        int
+
+	// Normalize transforms string fields before they are written
+	Normalize Normalization
+	// Control is the policy for control characters in string fields
+	Control ControlPolicy
+	// Overflow is the policy for values longer than Size
+	Overflow Overflow
+	// Sign is where numeric fields write their sign
+	Sign Sign
+	// Encoding is how the field is stored; for binary encodings Size is
+	// counted in bytes
+	Encoding Encoding
+
+	// DateLayout is the time.Format layout used by time.Time fields
+	DateLayout string
+	// ZeroDate tells which contents of time.Time fields mean no date
+	ZeroDate ZeroDate
+	// Location is the timezone in which time.Time fields are interpreted
+	Location *time.Location
 }
 
 // Marshal parsers all structs and transform into one string with all lines
 func Marshal(v interface{}) (string, error) {
 	var lines strings.Builder
 
-	rv := reflect.ValueOf(v)
-
-	switch rv.Kind() {
-	case reflect.Struct:
-		l, err := marshalStruct(rv)
-
-		if err != nil {
-			return "", err
-		}
-
-		lines.WriteString(l)
-	case reflect.Slice:
-		for i := 0; i < rv.Len(); i++ {
-			l, err := marshalStruct(rv.Index(i))
-
-			if err != nil {
-				return "", err
-			}
-
-			lines.WriteString(l)
-
-			if i != (rv.Len() - 1) {
-				lines.WriteString("\n")
-			}
-		}
+	if err := NewEncoder(&lines).Encode(v); err != nil {
+		return "", err
 	}
 
 	return lines.String(), nil
 }
 
-func marshalStruct(rv reflect.Value) (string, error) {
-	var c TagCollection
-
-	t := rv.Type()
-
-	c, err := ParseTags(t)
+func marshalStruct(rv reflect.Value, o *encodeOptions) (string, error) {
+	s, err := cachedSchema(rv.Type())
 
 	if err != nil {
 		return "", err
 	}
 
-	content, err := ParseValue(rv, c)
-
-	return content, err
+	return s.encode(rv, o)
 }
 
 // Unmarshal parses a string with all lines and transforms it into the appropriate struct or slice of structs
 func Unmarshal(data string, v interface{}) error {
 	rv := reflect.ValueOf(v)
 	if rv.Kind() != reflect.Ptr || rv.IsNil() {
-		return errors.New("v must be a non-nil pointer")
+		return ErrInvalidTarget
 	}
 
 	rv = rv.Elem()
-	lines := strings.Split(data, "\n")
+	lines := splitLines(data)
 
 	switch rv.Kind() {
 	case reflect.Struct:
 		if len(lines) != 1 {
-			return errors.New("expected single line for struct")
+			return errors.New("posline: expected single line for struct")
+		}
+		if err := unmarshalStruct(lines[0], rv, &decodeOptions{}); err != nil {
+			return &LineError{Line: 1, Err: err}
 		}
-		return unmarshalStruct(lines[0], rv)
 	case reflect.Slice:
 		sliceType := rv.Type().Elem()
-		for _, line := range lines {
-			elem := reflect.New(sliceType).Elem()
-			if err := unmarshalStruct(line, elem); err != nil {
-				return err
+		for i, line := range lines {
+			elem, target := newElem(sliceType)
+			if err := unmarshalStruct(line, target, &decodeOptions{}); err != nil {
+				return &LineError{Line: i + 1, Err: err}
 			}
 			rv.Set(reflect.Append(rv, elem))
 		}
 	default:
-		return errors.New("unsupported type")
+		return ErrUnsupportedType
 	}
 
 	return nil
 }
 
-func unmarshalStruct(line string, rv reflect.Value) error {
-	var c TagCollection
+// newElem returns a new element of a slice of t and the value to decode
+// into, which is the struct it points to for slices of pointers
+func newElem(t reflect.Type) (elem, target reflect.Value) {
+	if t.Kind() == reflect.Ptr {
+		elem = reflect.New(t.Elem())
+		return elem, elem.Elem()
+	}
+
+	elem = reflect.New(t).Elem()
+
+	return elem, elem
+}
+
+// recordOf returns the record struct of v, through any pointers to it
+func recordOf(v interface{}) reflect.Type {
+	t := reflect.TypeOf(v)
+
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
 
-	t := rv.Type()
+// utf8BOM is the byte order mark some editors write at the start of UTF-8 files
+const utf8BOM = "\xef\xbb\xbf"
+
+// splitLines splits data on "\n" or "\r\n", ignoring a byte order mark at
+// the start and the terminator after the last line
+func splitLines(data string) []string {
+	data = strings.TrimPrefix(data, utf8BOM)
+	data = trimTerminator(data)
+
+	lines := strings.Split(data, "\n")
+
+	for i := 0; i < len(lines)-1; i++ {
+		lines[i] = strings.TrimSuffix(lines[i], "\r")
+	}
+
+	return lines
+}
+
+// trimTerminator removes a trailing "\n" or "\r\n"
+func trimTerminator(line string) string {
+	if !strings.HasSuffix(line, "\n") {
+		return line
+	}
+
+	return strings.TrimSuffix(line[:len(line)-1], "\r")
+}
 
-	c, err := ParseTags(t)
+func unmarshalStruct(line string, rv reflect.Value, o *decodeOptions) error {
+	s, err := cachedSchema(rv.Type())
 
 	if err != nil {
 		return err
 	}
 
-	return UnparseValue(rv, c, line)
+	return s.decode(rv, line, o)
 }
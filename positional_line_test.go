@@ -2,8 +2,10 @@ package positional_line_test
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/vert-capital/positional_line"
 )
 
@@ -158,22 +160,22 @@ func TestUnmarshalWithIntField(t *testing.T) {
 	}
 }
 
-// func TestUnmarshalWithShortLine(t *testing.T) {
-// 	line := "short"
+func TestUnmarshalWithShortLine(t *testing.T) {
+	line := "short"
 
-// 	type TestStruct struct {
-// 		Field1 string `positional:"10"`
-// 		Field2 int    `positional:"5,leftpad"`
-// 	}
+	type TestStruct struct {
+		Field1 string `positional:"10"`
+		Field2 int    `positional:"5,leftpad"`
+	}
 
-// 	var test TestStruct
+	var test TestStruct
 
-// 	err := positional_line.Unmarshal(line, &test)
+	err := positional_line.Unmarshal(line, &test)
 
-// 	if err == nil {
-// 		t.Errorf("Expected error for short line, but got none")
-// 	}
-// }
+	if err == nil {
+		t.Errorf("Expected error for short line, but got none")
+	}
+}
 
 func TestUnmarshalWithInvalidInt(t *testing.T) {
 	line := "hello     abcde"
@@ -487,3 +489,200 @@ func TestUnmarshalWithAllVariations(t *testing.T) {
 		t.Errorf("Unmarshal(%v) = %v, expected %v", line, test.Field9, 132546)
 	}
 }
+
+func TestDecimalsRoundTrip(t *testing.T) {
+	type TestStruct struct {
+		Amount   float64 `positional:"10,decimals=2,zerofill,leftpad"`
+		Rate     float64 `positional:"8,decimals=5,zerofill,leftpad"`
+		Quantity float64 `positional:"4,decimals=0,zerofill,leftpad"`
+		Cents    int64   `positional:"10,decimals=2,zerofill,leftpad"`
+	}
+
+	input := TestStruct{Amount: 123, Rate: 0.01234, Quantity: 7, Cents: 12345}
+
+	line, err := positional_line.Marshal(input)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "0000012300"+"00001234"+"0007"+"0000012345", line)
+
+	var output TestStruct
+
+	assert.Nil(t, positional_line.Unmarshal(line, &output))
+	assert.Equal(t, input, output)
+}
+
+func TestDecimalsUnmarshalShortValue(t *testing.T) {
+	type TestStruct struct {
+		Amount float64 `positional:"6,decimals=3,leftpad"`
+	}
+
+	var test TestStruct
+
+	assert.Nil(t, positional_line.Unmarshal("    45", &test))
+	assert.Equal(t, 0.045, test.Amount)
+}
+
+func TestDecimalsInvalidTag(t *testing.T) {
+	type TestStruct struct {
+		Amount float64 `positional:"6,decimals=x"`
+	}
+
+	_, err := positional_line.Marshal(TestStruct{})
+
+	assert.NotNil(t, err)
+}
+
+func TestUnmarshalTerminatorTolerance(t *testing.T) {
+	type TestStruct struct {
+		Field1 string `positional:"10"`
+		Field2 int    `positional:"5,leftpad"`
+	}
+
+	var records []TestStruct
+
+	assert.Nil(t, positional_line.Unmarshal("\xef\xbb\xbfhello     12345\r\nworld        67\r\n", &records))
+	assert.Equal(t, []TestStruct{{"hello", 12345}, {"world", 67}}, records)
+
+	var record TestStruct
+
+	assert.Nil(t, positional_line.Unmarshal("hello     12345\n", &record))
+	assert.Equal(t, TestStruct{"hello", 12345}, record)
+
+	assert.NotNil(t, positional_line.Unmarshal("hello     12345\n\n", &records))
+}
+
+type fillerConstRecord struct {
+	Type    string `positional:"1,const=0"`
+	Kind    string `positional:"7,const=REMESSA"`
+	_       string `positional:"2"`
+	Bank    int    `positional:"3,leftpad,zerofill,const=341"`
+	_       string `positional:"3,pad=*,const=ABC"`
+	Company string `positional:"5"`
+	Spare   string `positional:"2,filler,pad=9"`
+}
+
+func TestMarshalFillerAndConst(t *testing.T) {
+	result, err := positional_line.Marshal(fillerConstRecord{Company: "ACME", Spare: "xx"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "0"+"REMESSA"+"  "+"341"+"ABC"+"ACME "+"99", result)
+}
+
+func TestUnmarshalFillerAndConst(t *testing.T) {
+	var record fillerConstRecord
+
+	err := positional_line.Unmarshal("0REMESSAxx341ABCACME 12", &record)
+
+	assert.Nil(t, err)
+	assert.Equal(t, fillerConstRecord{Type: "0", Kind: "REMESSA", Bank: 341, Company: "ACME"}, record)
+}
+
+func TestUnmarshalConstMismatch(t *testing.T) {
+	var record fillerConstRecord
+
+	err := positional_line.Unmarshal("0RETORNO  237ABCACME 99", &record)
+
+	var fieldErr *positional_line.FieldError
+
+	assert.ErrorIs(t, err, positional_line.ErrConstantMismatch)
+	assert.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "Kind", fieldErr.Field)
+	assert.Equal(t, "RETORNO", fieldErr.Value)
+}
+
+func TestConstInvalidTag(t *testing.T) {
+	type record struct {
+		Kind string `positional:"7,const="`
+	}
+
+	_, err := positional_line.Marshal(record{})
+
+	assert.ErrorIs(t, err, positional_line.ErrInvalidTag)
+}
+
+type nestedBase struct {
+	Type string `positional:"1"`
+}
+
+type nestedAddress struct {
+	City  string `positional:"6"`
+	State string `positional:"2"`
+}
+
+type nestedRecord struct {
+	nestedBase
+	Name     string `positional:"5"`
+	Address  nestedAddress
+	Billing  *nestedAddress
+	Amount   *int    `positional:"4,leftpad,zerofill"`
+	Note     *string `positional:"3"`
+	internal nestedAddress
+}
+
+func TestParseTagsNested(t *testing.T) {
+	tags, err := positional_line.ParseTags(reflect.TypeOf(&nestedRecord{}))
+
+	assert.Nil(t, err)
+
+	var names []string
+	for _, tag := range tags.Tags {
+		names = append(names, tag.Name)
+	}
+
+	assert.Equal(t, []string{"Type", "Name", "Address.City", "Address.State", "Billing.City", "Billing.State", "Amount", "Note"}, names)
+}
+
+func TestMarshalNested(t *testing.T) {
+	amount := 42
+
+	result, err := positional_line.Marshal([]*nestedRecord{
+		{
+			nestedBase: nestedBase{"1"},
+			Name:       "ana",
+			Address:    nestedAddress{"Recife", "PE"},
+			Billing:    &nestedAddress{"Olinda", "PE"},
+			Amount:     &amount,
+		},
+		{nestedBase: nestedBase{"2"}, Name: "bia"},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "1ana  RecifePEOlindaPE0042   \n2bia                         ", result)
+}
+
+func TestUnmarshalNested(t *testing.T) {
+	var records []*nestedRecord
+
+	err := positional_line.Unmarshal("1ana  RecifePEOlindaPE0042abc\n2bia                         ", &records)
+
+	amount := 42
+	note := "abc"
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*nestedRecord{
+		{
+			nestedBase: nestedBase{"1"},
+			Name:       "ana",
+			Address:    nestedAddress{"Recife", "PE"},
+			Billing:    &nestedAddress{"Olinda", "PE"},
+			Amount:     &amount,
+			Note:       &note,
+		},
+		{nestedBase: nestedBase{"2"}, Name: "bia"},
+	}, records)
+}
+
+func TestUnmarshalNestedFieldError(t *testing.T) {
+	var record nestedRecord
+
+	err := positional_line.Unmarshal("1ana  RecifePEOlindaPE00x2   ", &record)
+
+	var fieldErr *positional_line.FieldError
+
+	assert.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "Amount", fieldErr.Field)
+
+	err = positional_line.Unmarshal("1ana  RecifePEOlindaPE", &record)
+
+	assert.ErrorIs(t, err, positional_line.ErrInvalidLineSize)
+}
@@ -0,0 +1,645 @@
+package positional_line
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+type encodeFunc func(v reflect.Value, t Tag) (string, error)
+
+type decodeFunc func(v reflect.Value, t Tag, content string) error
+
+// schema is the compiled form of a record struct: the fields that carry a tag,
+// their offsets in the line and the functions that encode and decode them
+type schema struct {
+	fields []field
+	size   int
+
+	// nested are the pointers to nested structs, outermost first
+	nested []nested
+
+	// totals is set when a field holds a count or a sum
+	totals bool
+}
+
+// nested is a pointer to a nested struct, left nil when decoding a line in
+// which all the columns of its fields are blank
+type nested struct {
+	index  []int
+	fields []int
+}
+
+type field struct {
+	Tag
+	index    []int
+	sumKey   string
+	typ      reflect.Type
+	pointer  bool
+	start    int
+	end      int
+	fill     string
+	numeric  bool
+	unsigned bool
+	encode   encodeFunc
+	decode   decodeFunc
+}
+
+// encodeOptions are the Encoder settings applied to every record
+type encodeOptions struct {
+	overflow  Overflow
+	unit      WidthUnit
+	normalize Normalization
+	control   ControlPolicy
+
+	// seq is written in seq fields when numbered is set
+	seq      int
+	numbered bool
+
+	// totals are written in the fields that hold counts and sums, batch
+	// being the record struct that starts a batch
+	totals *aggregator
+	batch  reflect.Type
+}
+
+// decodeOptions are the Decoder settings applied to every record
+type decodeOptions struct {
+	unit WidthUnit
+
+	// allowShort and allowLong accept lines shorter or longer than the
+	// record, reporting them to warn
+	allowShort bool
+	allowLong  bool
+	warn       func(error)
+}
+
+// schemas caches the schema of every record type parsed from its own tags
+var schemas sync.Map
+
+// cachedSchema returns the schema of t, parsing its tags only once
+func cachedSchema(t reflect.Type) (*schema, error) {
+	if s, ok := schemas.Load(t); ok {
+		return s.(*schema), nil
+	}
+
+	c, err := ParseTags(t)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := compileSchema(t, c)
+
+	if err != nil {
+		return nil, err
+	}
+
+	actual, _ := schemas.LoadOrStore(t, s)
+
+	return actual.(*schema), nil
+}
+
+// compileSchema resolves the tags of line against the fields of t, in the
+// order the fields are declared, nested structs included
+func compileSchema(t reflect.Type, line TagCollection) (*schema, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("posline: %v is not a struct", t)
+	}
+
+	// fillers are all named _, so their tags are taken in order
+	tags := make(map[string][]Tag)
+
+	for _, tg := range line.Tags {
+		tags[tg.Name] = append(tags[tg.Name], tg)
+	}
+
+	s := &schema{}
+
+	for _, sf := range recordFields(t) {
+		queue := tags[sf.name]
+
+		if len(queue) == 0 {
+			continue
+		}
+
+		tg := queue[0]
+		tags[sf.name] = queue[1:]
+
+		ft := sf.Type
+		pointer := dereferenced(ft)
+
+		if pointer {
+			ft = ft.Elem()
+		}
+
+		start := s.size
+
+		if tg.Start > 0 {
+			if tg.Start-1 < s.size {
+				return nil, fmt.Errorf("%w: field %s starts at column %d, overlapping the previous field", ErrInvalidPosition, tg.Name, tg.Start)
+			}
+
+			start = tg.Start - 1
+		}
+
+		f := field{
+			Tag:      tg,
+			index:    sf.index,
+			sumKey:   t.Name() + "." + tg.Name,
+			typ:      ft,
+			pointer:  pointer,
+			start:    start,
+			end:      start + tg.Size,
+			fill:     fillOf(tg),
+			numeric:  isNumeric(ft),
+			unsigned: isUnsigned(ft),
+			encode:   encoderFor(ft),
+			decode:   decoderFor(ft),
+		}
+
+		s.addNested(t, sf.index, len(s.fields))
+		s.fields = append(s.fields, f)
+		s.size = f.end
+		s.totals = s.totals || tg.Aggregate.Kind != AggregateNone
+	}
+
+	if s.totals {
+		aggregated.Store(true)
+	}
+
+	return s, nil
+}
+
+// dereferenced reports whether fields of t are encoded and decoded through
+// the value they point to, which is every pointer but *time.Time
+func dereferenced(t reflect.Type) bool {
+	return t.Kind() == reflect.Ptr && !isTime(t)
+}
+
+// addNested adds the field at position i in the schema, found by index from
+// t, to the nested pointers it is reached through
+func (s *schema) addNested(t reflect.Type, index []int, i int) {
+	for k := range index[:len(index)-1] {
+		t = t.Field(index[k]).Type
+
+		if t.Kind() != reflect.Ptr {
+			continue
+		}
+
+		t = t.Elem()
+
+		j := slices.IndexFunc(s.nested, func(n nested) bool {
+			return slices.Equal(n.index, index[:k+1])
+		})
+
+		if j < 0 {
+			s.nested = append(s.nested, nested{index: slices.Clone(index[:k+1])})
+			j = len(s.nested) - 1
+		}
+
+		s.nested[j].fields = append(s.nested[j].fields, i)
+	}
+}
+
+// fillOf returns the character that pads the field of t
+func fillOf(t Tag) string {
+	if t.Pad != "" {
+		return t.Pad
+	}
+
+	if t.ZeroFill {
+		return "0"
+	}
+
+	return " "
+}
+
+// unpad removes the custom pad character from the padded side of content,
+// keeping the sign that leading, trailing and cd write outside the padding
+func unpad(content string, t Tag) string {
+	if t.Pad == "" || content == "" {
+		return content
+	}
+
+	switch t.Sign {
+	case SignLeading:
+		return content[:1] + trimPad(content[1:], t)
+	case SignTrailing, SignCreditDebit:
+		n := len(content) - 1
+		return trimPad(content[:n], t) + content[n:]
+	}
+
+	return trimPad(content, t)
+}
+
+// trimPad removes the custom pad character from the padded side of digits
+func trimPad(digits string, t Tag) string {
+	if t.LeftPad {
+		return strings.TrimLeft(digits, t.Pad)
+	}
+
+	return strings.TrimRight(digits, t.Pad)
+}
+
+// unpadded wraps decode so it receives content without the custom pad
+func unpadded(decode decodeFunc) decodeFunc {
+	return func(v reflect.Value, t Tag, content string) error {
+		return decode(v, t, unpad(content, t))
+	}
+}
+
+// isNumeric reports whether t is written as a number
+func isNumeric(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return !implements(t, positionalMarshalerType) && !implements(t, textMarshalerType)
+	}
+
+	return false
+}
+
+// isUnsigned reports whether t is an unsigned integer
+func isUnsigned(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+
+	return false
+}
+
+// encoderFor returns the function that converts values of t into content
+func encoderFor(t reflect.Type) encodeFunc {
+	if implements(t, positionalMarshalerType) {
+		return encodePositionalMarshaler
+	}
+
+	// time.Time is a TextMarshaler too, but it is formatted by the date layout
+	if isTime(t) {
+		return encodeTime
+	}
+
+	if implements(t, textMarshalerType) {
+		return encodeTextMarshaler
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return encodeString
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return encodeInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return encodeUint
+	case reflect.Float32, reflect.Float64:
+		return encodeFloat
+	case reflect.Bool:
+		return encodeBool
+	}
+
+	return encodeNothing
+}
+
+// decoderFor returns the function that parses content into values of t.
+// Apart from PositionalUnmarshaler, which gets the raw field, every decoder
+// receives the content without its custom pad character.
+func decoderFor(t reflect.Type) decodeFunc {
+	if reflect.PointerTo(t).Implements(positionalUnmarshalerType) {
+		return decodePositionalUnmarshaler
+	}
+
+	return unpadded(basicDecoderFor(t))
+}
+
+func basicDecoderFor(t reflect.Type) decodeFunc {
+	if isTime(t) {
+		return decodeTime
+	}
+
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return decodeTextUnmarshaler
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return decodeString
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return decodeInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return decodeUint
+	case reflect.Float32, reflect.Float64:
+		return decodeFloat
+	case reflect.Bool:
+		return decodeBool
+	}
+
+	return decodeNothing
+}
+
+// locate returns the field f of the record rv as declared, following nested
+// structs. Nil pointers to nested structs are allocated when alloc is set;
+// otherwise ok is false, as the field has no value.
+func (f *field) locate(rv reflect.Value, alloc bool) (reflect.Value, bool) {
+	return walk(rv, f.index, alloc)
+}
+
+// walk follows index from rv; see locate
+func walk(rv reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	v := rv
+
+	for _, i := range index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc {
+					return v, false
+				}
+
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(i)
+	}
+
+	return v, true
+}
+
+// value returns the field f of the record rv to be encoded or decoded,
+// dereferencing pointer fields; see locate
+func (f *field) value(rv reflect.Value, alloc bool) (reflect.Value, bool) {
+	v, ok := f.locate(rv, alloc)
+
+	if !ok || !f.pointer {
+		return v, ok
+	}
+
+	if v.IsNil() {
+		if !alloc {
+			return v, false
+		}
+
+		v.Set(reflect.New(v.Type().Elem()))
+	}
+
+	return v.Elem(), true
+}
+
+// error wraps err into a FieldError describing f
+func (f *field) error(value string, err error) error {
+	return &FieldError{
+		Field: f.Name,
+		Start: f.start,
+		End:   f.end,
+		Value: value,
+		Err:   err,
+	}
+}
+
+// encode writes every field of rv padded to its size
+func (s *schema) encode(rv reflect.Value, o *encodeOptions) (string, error) {
+	var content strings.Builder
+
+	content.Grow(s.size)
+
+	// pos is the width written so far, used to blank the unused columns
+	// before fields placed by their start column
+	pos := 0
+
+	for _, f := range s.fields {
+		if f.start > pos {
+			content.WriteString(strings.Repeat(" ", f.start-pos))
+		}
+
+		pos = f.end
+
+		var fieldContent string
+
+		switch {
+		case f.Const != "":
+			fieldContent = f.Const
+		case f.Seq && o.numbered:
+			fieldContent = strconv.Itoa(o.seq)
+		case f.Aggregate.Kind != AggregateNone && o.totals != nil:
+			var err error
+
+			total := o.totals.value(f.Aggregate, f.typ, f.Decimals, rv, s, o.batch)
+
+			if fieldContent, err = f.encode(total, f.Tag); err != nil {
+				return "", f.error(fieldContent, err)
+			}
+		case f.Filler:
+		default:
+			v, ok := f.value(rv, false)
+
+			// nil pointers are written as blanks
+			if !ok {
+				content.WriteString(strings.Repeat(" ", f.Size))
+				continue
+			}
+
+			tg := f.Tag
+			tg.Normalize |= o.normalize
+
+			if tg.Control == ControlDefault {
+				tg.Control = o.control
+			}
+
+			var err error
+
+			if fieldContent, err = f.encode(v, tg); err != nil {
+				// report the string that was rejected, not the empty result
+				if v.Kind() == reflect.String {
+					fieldContent = v.String()
+				}
+
+				return "", f.error(fieldContent, err)
+			}
+		}
+
+		fline, err := f.format(fieldContent, o)
+
+		if err != nil {
+			return "", f.error(fieldContent, err)
+		}
+
+		content.WriteString(fline)
+	}
+
+	return content.String(), nil
+}
+
+// format lays content out in the field: packed for binary encodings,
+// otherwise signed, fitted and padded to its size
+func (f *field) format(content string, o *encodeOptions) (string, error) {
+	if f.Encoding != EncodingText {
+		return f.pack(content)
+	}
+
+	lead, digits, trail := f.signParts(content)
+	size := f.Size - len(lead) - len(trail)
+
+	fitted, err := f.fit(digits, size, o)
+
+	if err != nil {
+		return "", err
+	}
+
+	var fline string
+	if f.LeftPad {
+		fline, err = o.unit.padLeft(fitted, size, f.fill)
+	} else {
+		fline, err = o.unit.padRight(fitted, size, f.fill)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return lead + fline + trail, nil
+}
+
+// warning reports a tolerated problem with a line
+func (o *decodeOptions) warning(err error) {
+	if o.warn != nil {
+		o.warn(err)
+	}
+}
+
+// decode parses content into the fields of rv
+func (s *schema) decode(rv reflect.Value, content string, o *decodeOptions) error {
+	size := len(content)
+
+	if o.unit == WidthRunes {
+		size = utf8.RuneCountInString(content)
+	}
+
+	switch {
+	case size < s.size && o.allowShort:
+		o.warning(fmt.Errorf("%w: expected %d, got %d", ErrShortLine, s.size, size))
+		content += strings.Repeat(" ", s.size-size)
+	case size > s.size && o.allowLong:
+		o.warning(fmt.Errorf("%w: expected %d, got %d", ErrLongLine, s.size, size))
+	case size != s.size:
+		return fmt.Errorf("%w: expected %d, got %d", ErrInvalidLineSize, s.size, size)
+	}
+
+	valid := o.unit == WidthBytes && utf8.ValidString(content)
+
+	// offsets holds the byte offset of every rune, when runes are counted
+	// and some of them take more than a byte
+	var offsets []int
+
+	if o.unit == WidthRunes && utf8.RuneCountInString(content) != len(content) {
+		offsets = make([]int, 0, len(content)+1)
+
+		for i := range content {
+			offsets = append(offsets, i)
+		}
+
+		offsets = append(offsets, len(content))
+	}
+
+	slice := func(f *field) string {
+		if offsets != nil {
+			return content[offsets[f.start]:offsets[f.end]]
+		}
+
+		return content[f.start:f.end]
+	}
+
+	// nested structs written as blanks are left nil, and so are their fields
+	var skip []bool
+
+	for _, n := range s.nested {
+		blank := true
+
+		for _, i := range n.fields {
+			blank = blank && strings.TrimSpace(slice(&s.fields[i])) == ""
+		}
+
+		if !blank {
+			continue
+		}
+
+		if skip == nil {
+			skip = make([]bool, len(s.fields))
+		}
+
+		for _, i := range n.fields {
+			skip[i] = true
+		}
+
+		if v, ok := walk(rv, n.index, false); ok {
+			v.Set(reflect.Zero(v.Type()))
+		}
+	}
+
+	for i := range s.fields {
+		f := &s.fields[i]
+
+		if skip != nil && skip[i] {
+			continue
+		}
+
+		value := slice(f)
+
+		if valid && f.Encoding == EncodingText && !utf8.ValidString(value) {
+			return f.error(value, ErrSplitCharacter)
+		}
+
+		// the bytes of packed fields are lost once read as runes
+		if o.unit == WidthRunes && f.Encoding != EncodingText {
+			return f.error(value, ErrBinaryWidth)
+		}
+
+		if f.Const != "" {
+			expected, err := f.format(f.Const, &encodeOptions{unit: o.unit})
+
+			if err != nil {
+				return f.error(value, err)
+			}
+
+			if value != expected {
+				return f.error(value, fmt.Errorf("%w: expected %q", ErrConstantMismatch, expected))
+			}
+		}
+
+		if f.Filler {
+			continue
+		}
+
+		// blank pointer fields are left nil
+		if f.pointer && strings.TrimSpace(value) == "" {
+			if v, ok := f.locate(rv, false); ok {
+				v.Set(reflect.Zero(v.Type()))
+			}
+
+			continue
+		}
+
+		text := value
+
+		if f.Encoding != EncodingText {
+			var err error
+
+			if text, err = f.unpack(value); err != nil {
+				return f.error(value, err)
+			}
+		}
+
+		v, _ := f.value(rv, true)
+
+		if err := f.decode(v, f.Tag, text); err != nil {
+			return f.error(value, err)
+		}
+	}
+
+	return nil
+}
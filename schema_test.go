@@ -0,0 +1,77 @@
+package positional_line_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vert-capital/positional_line"
+)
+
+type schemaTestStruct struct {
+	Field1 string  `positional:"10"`
+	Field2 float64 `positional:"8,decimals=2,zerofill,leftpad"`
+	Field3 int     `positional:"6,zerofill,leftpad"`
+	Field4 bool    `positional:"1"`
+	Field5 string
+}
+
+func TestMarshalConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			line, err := positional_line.Marshal(schemaTestStruct{"hello", 1.5, i, true, "ignored"})
+			assert.Nil(t, err)
+
+			var test schemaTestStruct
+			assert.Nil(t, positional_line.Unmarshal(line, &test))
+			assert.Equal(t, i, test.Field3)
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	records := make([]schemaTestStruct, 1000)
+
+	for i := range records {
+		records[i] = schemaTestStruct{"hello", float64(i) / 3, i, i%2 == 0, ""}
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := positional_line.Marshal(records); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	records := make([]schemaTestStruct, 1000)
+
+	for i := range records {
+		records[i] = schemaTestStruct{"hello", float64(i) / 3, i, i%2 == 0, ""}
+	}
+
+	data, err := positional_line.Marshal(records)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var test []schemaTestStruct
+
+		if err := positional_line.Unmarshal(data, &test); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package positional_line
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Scope is the range of records over which sequences are counted
+type Scope int
+
+const (
+	// ScopeFile counts over every record of the file
+	ScopeFile Scope = iota
+	// ScopeBatch restarts the count after every batch header
+	ScopeBatch
+)
+
+var scopeNames = map[string]Scope{
+	"file":  ScopeFile,
+	"batch": ScopeBatch,
+}
+
+func parseScope(value string) (Scope, error) {
+	s, ok := scopeNames[value]
+
+	if !ok {
+		return ScopeFile, fmt.Errorf("%w: scope %q", ErrInvalidTag, value)
+	}
+
+	return s, nil
+}
+
+// sequence tracks the number of the next record in its scope
+type sequence struct {
+	start int
+	scope Scope
+	count int
+}
+
+// number returns the number of the next record, of type t, and advances the
+// sequence. With ScopeBatch, a batch header restarts the sequence and is not
+// numbered itself. t is nil for a line whose record struct is unknown.
+func (s *sequence) number(t, batch reflect.Type) (int, bool) {
+	if s.scope == ScopeBatch && t != nil && t == batch {
+		s.count = 0
+		return 0, false
+	}
+
+	n := s.start + s.count
+	s.count++
+
+	return n, true
+}
+
+// check verifies that the seq fields of rv hold n. On a gap or a duplicate
+// the sequence carries on from the number found, so that a single misplaced
+// record is reported only once.
+func (s *sequence) check(rv reflect.Value, n int) error {
+	sc, err := cachedSchema(rv.Type())
+
+	if err != nil {
+		return err
+	}
+
+	for _, f := range sc.fields {
+		if !f.Seq {
+			continue
+		}
+
+		v, ok := f.value(rv, false)
+
+		var got int
+		switch {
+		case !ok:
+		case f.unsigned:
+			got = int(v.Uint())
+		default:
+			got = int(v.Int())
+		}
+
+		if got != n {
+			s.count = got - s.start + 1
+			return f.error(strconv.Itoa(got), fmt.Errorf("%w: expected %d", ErrSequence, n))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package positional_line_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vert-capital/positional_line"
+)
+
+type seqRecord struct {
+	Name string `positional:"5"`
+	Seq  int    `positional:"6,leftpad,zerofill,seq"`
+}
+
+type seqBatchHeader struct {
+	Type string `positional:"1"`
+	Lot  int    `positional:"4,leftpad,zerofill"`
+}
+
+type seqBatchDetail struct {
+	Type string `positional:"1"`
+	Seq  uint   `positional:"4,leftpad,zerofill,seq"`
+}
+
+var seqLayoutRecords = map[string]interface{}{
+	"1": seqBatchHeader{},
+	"3": seqBatchDetail{},
+}
+
+func TestMarshalSequence(t *testing.T) {
+	result, err := positional_line.Marshal([]seqRecord{{"a", 0}, {"b", 99}, {"c", 0}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "a    000001\nb    000002\nc    000003", result)
+}
+
+func TestEncoderSequenceAcrossCalls(t *testing.T) {
+	var out strings.Builder
+
+	enc := positional_line.NewEncoder(&out)
+	enc.SetSequence(0, positional_line.ScopeFile)
+
+	assert.Nil(t, enc.Encode(seqRecord{Name: "a"}))
+	assert.Nil(t, enc.Encode([]seqRecord{{Name: "b"}, {Name: "c"}}))
+	assert.Equal(t, "a    000000\nb    000001\nc    000002", out.String())
+}
+
+func TestEncoderSequenceByBatch(t *testing.T) {
+	var out strings.Builder
+
+	enc := positional_line.NewEncoder(&out)
+	enc.SetSequence(1, positional_line.ScopeBatch)
+	enc.SetBatchHeader(seqBatchHeader{})
+
+	assert.Nil(t, enc.Encode([]interface{}{
+		seqBatchHeader{"1", 1},
+		seqBatchDetail{Type: "3"},
+		seqBatchDetail{Type: "3"},
+		seqBatchHeader{"1", 2},
+		seqBatchDetail{Type: "3"},
+	}))
+	assert.Equal(t, "10001\n30001\n30002\n10002\n30001", out.String())
+}
+
+func TestEncoderBatchHeaderPointer(t *testing.T) {
+	var out strings.Builder
+
+	enc := positional_line.NewEncoder(&out)
+	enc.SetSequence(1, positional_line.ScopeBatch)
+	enc.SetBatchHeader(&seqBatchHeader{})
+
+	assert.Nil(t, enc.Encode([]interface{}{
+		seqBatchHeader{"1", 1},
+		seqBatchDetail{Type: "3"},
+		seqBatchHeader{"1", 2},
+		seqBatchDetail{Type: "3"},
+	}))
+	assert.Equal(t, "10001\n30001\n10002\n30001", out.String())
+}
+
+func TestDecoderSequence(t *testing.T) {
+	dec := positional_line.NewDecoder(strings.NewReader("a    000001\nb    000002"))
+
+	var records []seqRecord
+
+	assert.Nil(t, dec.Decode(&records))
+	assert.Equal(t, []seqRecord{{"a", 1}, {"b", 2}}, records)
+}
+
+func TestDecoderSequenceGapAndDuplicate(t *testing.T) {
+	dec := positional_line.NewDecoder(strings.NewReader("a    000001\nb    000003\nc    000004\nd    000004"))
+	dec.CollectErrors()
+
+	var records []seqRecord
+
+	err := dec.Decode(&records)
+
+	var errs positional_line.ErrorList
+
+	assert.ErrorAs(t, err, &errs)
+	assert.Len(t, errs, 2)
+	assert.Equal(t, 2, errs[0].Line)
+	assert.Equal(t, 4, errs[1].Line)
+	assert.ErrorIs(t, errs[0], positional_line.ErrSequence)
+	assert.Equal(t, `posline: line 2: field Seq (columns 6-11) "3": posline: record out of sequence: expected 2`, errs[0].Error())
+	assert.Equal(t, []seqRecord{{"a", 1}, {"c", 4}}, records)
+}
+
+func TestDecoderSequenceByBatch(t *testing.T) {
+	dec := positional_line.NewDecoder(strings.NewReader("10001\n30001\n30002\n10002\n30001\n30003"))
+	dec.UseLayout(newTestLayout(t, seqLayoutRecords))
+	dec.UseSequence(1, positional_line.ScopeBatch)
+	dec.UseBatchHeader(seqBatchHeader{})
+
+	var records []interface{}
+
+	err := dec.Decode(&records)
+
+	var lineErr *positional_line.LineError
+
+	assert.ErrorAs(t, err, &lineErr)
+	assert.Equal(t, 6, lineErr.Line)
+	assert.ErrorIs(t, err, positional_line.ErrSequence)
+}
+
+func TestDecoderSequenceSkipsUnknownRecords(t *testing.T) {
+	dec := positional_line.NewDecoder(strings.NewReader("30001\nX\n30003\n30004"))
+	dec.UseLayout(newTestLayout(t, seqLayoutRecords))
+	dec.CollectErrors()
+
+	var records []interface{}
+
+	err := dec.Decode(&records)
+
+	var errs positional_line.ErrorList
+
+	assert.ErrorAs(t, err, &errs)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, 2, errs[0].Line)
+	assert.NotErrorIs(t, errs[0], positional_line.ErrSequence)
+	assert.Len(t, records, 3)
+}
+
+func TestParseTagsInvalidSeq(t *testing.T) {
+	type record struct {
+		Seq string `positional:"6,seq"`
+	}
+
+	_, err := positional_line.Marshal(record{})
+
+	assert.ErrorIs(t, err, positional_line.ErrInvalidTag)
+}
+
+func TestEncoderSkipsFailedRecords(t *testing.T) {
+	type record struct {
+		A     int `positional:"1,leftpad"`
+		Seq   int `positional:"4,leftpad,zerofill,seq"`
+		Count int `positional:"2,leftpad,zerofill,count"`
+	}
+
+	var out strings.Builder
+
+	enc := positional_line.NewEncoder(&out)
+
+	assert.Nil(t, enc.Encode(record{A: 1}))
+	assert.ErrorIs(t, enc.Encode(record{A: 100}), positional_line.ErrOverflow)
+	assert.Nil(t, enc.Encode(record{A: 2}))
+	assert.Equal(t, "1000101\n2000202", out.String())
+}
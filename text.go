@@ -0,0 +1,123 @@
+package positional_line
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Normalization is a set of transformations applied to string fields before
+// they are written
+type Normalization int
+
+const (
+	// NormalizeUpper converts the text to uppercase
+	NormalizeUpper Normalization = 1 << iota
+	// NormalizeASCII transliterates accented letters to their ASCII base
+	// ("ção" becomes "cao") and strips any other non-ASCII character
+	NormalizeASCII
+)
+
+// ControlPolicy tells what to do with control characters, such as CR, LF and
+// tabs, found in string fields
+type ControlPolicy int
+
+const (
+	// ControlDefault is ControlError unless the Encoder sets another policy
+	ControlDefault ControlPolicy = iota
+	// ControlError raises ErrControlCharacter
+	ControlError
+	// ControlReplace replaces every control character with a space
+	ControlReplace
+	// ControlStrip removes every control character
+	ControlStrip
+)
+
+var controlNames = map[string]ControlPolicy{
+	"error":   ControlError,
+	"replace": ControlReplace,
+	"strip":   ControlStrip,
+}
+
+func parseControl(value string) (ControlPolicy, error) {
+	c, ok := controlNames[value]
+
+	if !ok {
+		return ControlDefault, fmt.Errorf("%w: control %q", ErrInvalidTag, value)
+	}
+
+	return c, nil
+}
+
+// sanitize applies the control characters policy to s
+func sanitize(s string, p ControlPolicy) (string, error) {
+	i := strings.IndexFunc(s, unicode.IsControl)
+
+	if i < 0 {
+		return s, nil
+	}
+
+	if p != ControlReplace && p != ControlStrip {
+		r, _ := utf8.DecodeRuneInString(s[i:])
+		return "", fmt.Errorf("%w: %q at position %d", ErrControlCharacter, r, utf8.RuneCountInString(s[:i])+1)
+	}
+
+	return strings.Map(func(r rune) rune {
+		if !unicode.IsControl(r) {
+			return r
+		}
+
+		if p == ControlReplace {
+			return ' '
+		}
+
+		return -1
+	}, s), nil
+}
+
+// transliterations maps non-ASCII characters to their closest ASCII text
+var transliterations = map[rune]string{
+	'À': "A", 'Á': "A", 'Â': "A", 'Ã': "A", 'Ä': "A", 'Å': "A", 'Æ': "AE",
+	'Ç': "C", 'È': "E", 'É': "E", 'Ê': "E", 'Ë': "E",
+	'Ì': "I", 'Í': "I", 'Î': "I", 'Ï': "I", 'Ð': "D", 'Ñ': "N",
+	'Ò': "O", 'Ó': "O", 'Ô': "O", 'Õ': "O", 'Ö': "O", 'Ø': "O",
+	'Ù': "U", 'Ú': "U", 'Û': "U", 'Ü': "U", 'Ý': "Y", 'Þ': "TH", 'ß': "ss",
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'æ': "ae",
+	'ç': "c", 'è': "e", 'é': "e", 'ê': "e", 'ë': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ð': "d", 'ñ': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ý': "y", 'þ': "th", 'ÿ': "y",
+	'Œ': "OE", 'œ': "oe", 'Š': "S", 'š': "s", 'Ž': "Z", 'ž': "z", 'Ÿ': "Y",
+	'ª': "a", 'º': "o", '‘': "'", '’': "'", '“': "\"", '”': "\"", '–': "-", '—': "-",
+}
+
+// normalize applies the transformations of n to s
+func normalize(s string, n Normalization) string {
+	if n&NormalizeASCII != 0 {
+		s = toASCII(s)
+	}
+
+	if n&NormalizeUpper != 0 {
+		s = strings.ToUpper(s)
+	}
+
+	return s
+}
+
+func toASCII(s string) string {
+	var b strings.Builder
+
+	b.Grow(len(s))
+
+	for _, r := range s {
+		if r < 0x80 {
+			b.WriteRune(r)
+			continue
+		}
+
+		b.WriteString(transliterations[r])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,101 @@
+package positional_line_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vert-capital/positional_line"
+)
+
+func TestMarshalNormalizeTags(t *testing.T) {
+	type TestStruct struct {
+		Name    string `positional:"20,upper,ascii"`
+		City    string `positional:"10,ascii"`
+		Street  string `positional:"10,upper"`
+		Comment string `positional:"10"`
+	}
+
+	result, err := positional_line.Marshal(TestStruct{"Associação Ñandú", "São Paulo", "rua ç", "ação"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "ASSOCIACAO NANDU    "+"Sao Paulo "+"RUA Ç     "+"ação      ", result)
+}
+
+func TestMarshalASCIIStripsUnsupported(t *testing.T) {
+	type TestStruct struct {
+		Name string `positional:"16,ascii"`
+	}
+
+	result, err := positional_line.Marshal(TestStruct{"“Straße” € 10—ok"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "\"Strasse\"  10-ok", result)
+}
+
+func TestEncoderSetNormalize(t *testing.T) {
+	type TestStruct struct {
+		Name   string `positional:"10"`
+		Amount int    `positional:"3,leftpad"`
+	}
+
+	var out strings.Builder
+
+	enc := positional_line.NewEncoder(&out)
+	enc.SetNormalize(positional_line.NormalizeUpper | positional_line.NormalizeASCII)
+
+	assert.Nil(t, enc.Encode(TestStruct{"João", 1}))
+	assert.Equal(t, "JOAO        1", out.String())
+}
+
+func TestConvertNormalize(t *testing.T) {
+	assert.Equal(t, "ACAO", positional_line.Convert(reflect.ValueOf("ação"), positional_line.Tag{
+		Normalize: positional_line.NormalizeUpper | positional_line.NormalizeASCII,
+	}))
+}
+
+func TestMarshalControlCharacters(t *testing.T) {
+	type TestStruct struct {
+		Address string `positional:"12"`
+	}
+
+	_, err := positional_line.Marshal(TestStruct{"Rua A\nCasa 2"})
+
+	var fieldErr *positional_line.FieldError
+
+	assert.ErrorIs(t, err, positional_line.ErrControlCharacter)
+	assert.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "Address", fieldErr.Field)
+	assert.Equal(t, "Rua A\nCasa 2", fieldErr.Value)
+	assert.Contains(t, err.Error(), `'\n' at position 6`)
+}
+
+func TestMarshalControlPolicyTags(t *testing.T) {
+	type TestStruct struct {
+		Replaced string `positional:"8,control=replace"`
+		Stripped string `positional:"8,control=strip"`
+	}
+
+	result, err := positional_line.Marshal(TestStruct{"a\r\nb\tc", "a\r\nb\tc"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "a  b c  "+"abc     ", result)
+}
+
+func TestEncoderSetControlPolicy(t *testing.T) {
+	type TestStruct struct {
+		Name    string `positional:"6"`
+		Address string `positional:"6,control=error"`
+	}
+
+	var out strings.Builder
+
+	enc := positional_line.NewEncoder(&out)
+	enc.SetControlPolicy(positional_line.ControlReplace)
+
+	assert.Nil(t, enc.Encode(TestStruct{"a\x00b", "c"}))
+	assert.Equal(t, "a b   c     ", out.String())
+
+	assert.ErrorIs(t, enc.Encode(TestStruct{"a", "c\x7f"}), positional_line.ErrControlCharacter)
+}
@@ -0,0 +1,131 @@
+package positional_line
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// ZeroDate tells which contents of a time.Time field mean there is no date,
+// decoded as the zero time or a nil *time.Time
+type ZeroDate int
+
+const (
+	// ZeroDateDefault treats blank contents as no date, and all-zero
+	// contents too when the layout has a date part, so that a 000000 time
+	// of day is still midnight
+	ZeroDateDefault ZeroDate = iota
+	// ZeroDateBlank only treats blank contents as no date
+	ZeroDateBlank
+	// ZeroDateZeros treats blank and all-zero contents as no date
+	ZeroDateZeros
+)
+
+var zeroDateNames = map[string]ZeroDate{
+	"blank": ZeroDateBlank,
+	"zeros": ZeroDateZeros,
+}
+
+func parseZeroDate(value string) (ZeroDate, error) {
+	z, ok := zeroDateNames[value]
+
+	if !ok {
+		return ZeroDateDefault, fmt.Errorf("%w: zerodate %q", ErrInvalidTag, value)
+	}
+
+	return z, nil
+}
+
+// hasDate reports whether layout writes the year, month or day
+func hasDate(layout string) bool {
+	a := time.Date(2001, 2, 3, 0, 0, 0, 0, time.UTC)
+	b := time.Date(2004, 5, 6, 0, 0, 0, 0, time.UTC)
+
+	return a.Format(layout) != b.Format(layout)
+}
+
+// noDate reports whether content stands for no date in a field of t
+func noDate(content string, t Tag) bool {
+	if strings.TrimSpace(content) == "" {
+		return true
+	}
+
+	switch t.ZeroDate {
+	case ZeroDateBlank:
+		return false
+	case ZeroDateZeros:
+		return strings.Trim(content, " 0") == ""
+	}
+
+	return strings.Trim(content, " 0") == "" && hasDate(dateLayout(t))
+}
+
+// isTime reports whether t is a time.Time or a *time.Time
+func isTime(t reflect.Type) bool {
+	return t == timeType || (t.Kind() == reflect.Ptr && t.Elem() == timeType)
+}
+
+func dateLayout(t Tag) string {
+	if t.DateLayout == "" {
+		return defaultDateLayout
+	}
+
+	return t.DateLayout
+}
+
+// encodeTime formats a time.Time or *time.Time field. Zero and nil dates are
+// left empty, so the field padding decides whether they are written as blanks
+// or, with zerofill, as zeros.
+func encodeTime(v reflect.Value, t Tag) (string, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+
+		v = v.Elem()
+	}
+
+	value := v.Interface().(time.Time)
+
+	if value.IsZero() {
+		return "", nil
+	}
+
+	if t.Location != nil {
+		value = value.In(t.Location)
+	}
+
+	return value.Format(dateLayout(t)), nil
+}
+
+// decodeTime parses a time.Time or *time.Time field. Contents that mean no
+// date, as told by the zerodate modifier, are decoded as the zero time or a
+// nil pointer.
+func decodeTime(v reflect.Value, t Tag, content string) error {
+	if noDate(content, t) {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	loc := t.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	value, err := time.ParseInLocation(dateLayout(t), strings.TrimSpace(content), loc)
+
+	if err != nil {
+		return err
+	}
+
+	if v.Kind() == reflect.Ptr {
+		v.Set(reflect.ValueOf(&value))
+	} else {
+		v.Set(reflect.ValueOf(value))
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package positional_line_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vert-capital/positional_line"
+)
+
+type timeTestStruct struct {
+	Date     time.Time  `positional:"8"`
+	Time     time.Time  `positional:"6,date=150405"`
+	Due      *time.Time `positional:"8,date=20060102,zerofill"`
+	Optional *time.Time `positional:"8,date=20060102"`
+}
+
+func TestMarshalTime(t *testing.T) {
+	due := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	result, err := positional_line.Marshal(timeTestStruct{
+		Date: time.Date(2024, 2, 15, 13, 45, 10, 0, time.UTC),
+		Time: time.Date(2024, 2, 15, 13, 45, 10, 0, time.UTC),
+		Due:  &due,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "15022024134510"+"20240301"+"        ", result)
+}
+
+func TestMarshalZeroTime(t *testing.T) {
+	type TestStruct struct {
+		Date  time.Time  `positional:"8,zerofill"`
+		Blank time.Time  `positional:"8"`
+		Nil   *time.Time `positional:"8,zerofill"`
+	}
+
+	result, err := positional_line.Marshal(TestStruct{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "00000000"+"        "+"00000000", result)
+}
+
+func TestUnmarshalTime(t *testing.T) {
+	var test timeTestStruct
+
+	err := positional_line.Unmarshal("15022024134510"+"20240301"+"00000000", &test)
+
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC), test.Date)
+	assert.Equal(t, time.Date(0, 1, 1, 13, 45, 10, 0, time.UTC), test.Time)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), *test.Due)
+	assert.Nil(t, test.Optional)
+}
+
+func TestUnmarshalBlankTime(t *testing.T) {
+	var test timeTestStruct
+
+	err := positional_line.Unmarshal("        "+"      "+"        "+"        ", &test)
+
+	assert.Nil(t, err)
+	assert.True(t, test.Date.IsZero())
+	assert.True(t, test.Time.IsZero())
+	assert.Nil(t, test.Due)
+	assert.Nil(t, test.Optional)
+}
+
+func TestUnmarshalInvalidTime(t *testing.T) {
+	var test timeTestStruct
+
+	err := positional_line.Unmarshal("31022024134510"+"20240301"+"        ", &test)
+
+	assert.NotNil(t, err)
+}
+
+func TestTimeLocation(t *testing.T) {
+	type TestStruct struct {
+		Date time.Time `positional:"12,date=020120061504,tz=America/Sao_Paulo"`
+	}
+
+	loc, err := time.LoadLocation("America/Sao_Paulo")
+	assert.Nil(t, err)
+
+	result, err := positional_line.Marshal(TestStruct{time.Date(2024, 2, 15, 13, 0, 0, 0, time.UTC)})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "150220241000", result)
+
+	var test TestStruct
+
+	assert.Nil(t, positional_line.Unmarshal(result, &test))
+	assert.Equal(t, loc, test.Date.Location())
+	assert.True(t, test.Date.Equal(time.Date(2024, 2, 15, 13, 0, 0, 0, time.UTC)))
+}
+
+func TestTimeInvalidLocation(t *testing.T) {
+	type TestStruct struct {
+		Date time.Time `positional:"8,tz=Nowhere/Invalid"`
+	}
+
+	_, err := positional_line.Marshal(TestStruct{})
+
+	assert.NotNil(t, err)
+}
+
+func TestTimeMidnight(t *testing.T) {
+	type TestStruct struct {
+		Time time.Time `positional:"6,date=150405"`
+	}
+
+	var test TestStruct
+
+	assert.Nil(t, positional_line.Unmarshal("000000", &test))
+	assert.Equal(t, time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC), test.Time)
+
+	result, err := positional_line.Marshal(test)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "000000", result)
+}
+
+func TestTimeZeroDateOption(t *testing.T) {
+	type TestStruct struct {
+		Blank *time.Time `positional:"8,date=20060102,zerodate=blank"`
+		Zeros time.Time  `positional:"6,date=150405,zerodate=zeros"`
+	}
+
+	var test TestStruct
+
+	err := positional_line.Unmarshal("00000000"+"000000", &test)
+
+	assert.NotNil(t, err)
+
+	err = positional_line.Unmarshal("        "+"000000", &test)
+
+	assert.Nil(t, err)
+	assert.Nil(t, test.Blank)
+	assert.True(t, test.Zeros.IsZero())
+}
+
+func TestTimeInvalidZeroDate(t *testing.T) {
+	type TestStruct struct {
+		Date time.Time `positional:"8,zerodate=never"`
+	}
+
+	_, err := positional_line.Marshal(TestStruct{})
+
+	assert.ErrorIs(t, err, positional_line.ErrInvalidTag)
+}
@@ -0,0 +1,70 @@
+package positional_line
+
+import (
+	"unicode/utf8"
+
+	"github.com/vert-capital/positional_line/pad"
+)
+
+// WidthUnit is the unit in which field sizes and offsets are counted
+type WidthUnit int
+
+const (
+	// WidthRunes counts characters, so "ã" takes one position
+	WidthRunes WidthUnit = iota
+	// WidthBytes counts bytes of the UTF-8 text, so "ã" takes two positions
+	WidthBytes
+)
+
+// len returns the width of s
+func (u WidthUnit) len(s string) int {
+	if u == WidthBytes {
+		return len(s)
+	}
+
+	return utf8.RuneCountInString(s)
+}
+
+// keepLeft returns the longest prefix of s that fits in size
+func (u WidthUnit) keepLeft(s string, size int) string {
+	if u == WidthBytes {
+		return pad.CutBytes(s, size)
+	}
+
+	return string([]rune(s)[:size])
+}
+
+// keepRight returns the longest suffix of s that fits in size
+func (u WidthUnit) keepRight(s string, size int) string {
+	if u == WidthBytes {
+		i := len(s) - size
+
+		for i < len(s) && !utf8.RuneStart(s[i]) {
+			i++
+		}
+
+		return s[i:]
+	}
+
+	runes := []rune(s)
+
+	return string(runes[len(runes)-size:])
+}
+
+// padLeft fills s on the left up to size
+func (u WidthUnit) padLeft(s string, size int, fill string) (string, error) {
+	if u == WidthBytes {
+		return pad.LeftBytes(s, size, fill)
+	}
+
+	return pad.Left(s, size, fill)
+}
+
+// padRight fills s on the right up to size
+func (u WidthUnit) padRight(s string, size int, fill string) (string, error) {
+	if u == WidthBytes {
+		return pad.RightBytes(s, size, fill)
+	}
+
+	return pad.Right(s, size, fill)
+}
@@ -0,0 +1,76 @@
+package positional_line_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vert-capital/positional_line"
+)
+
+type widthTestStruct struct {
+	Name string `positional:"6"`
+	City string `positional:"8,leftpad"`
+}
+
+func TestWidthRunesRoundTrip(t *testing.T) {
+	input := widthTestStruct{"João", "São José"}
+
+	line, err := positional_line.Marshal(input)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "João  São José", line)
+
+	var output widthTestStruct
+
+	assert.Nil(t, positional_line.Unmarshal(line, &output))
+	assert.Equal(t, input, output)
+}
+
+func TestWidthBytesRoundTrip(t *testing.T) {
+	input := []widthTestStruct{{"João", "São José"}, {"Conceição", "Itajaí"}}
+
+	var out strings.Builder
+
+	enc := positional_line.NewEncoder(&out)
+	enc.SetWidthUnit(positional_line.WidthBytes)
+
+	assert.Nil(t, enc.Encode(input))
+	assert.Equal(t, "João São Jos\n"+"Concei Itajaí", out.String())
+
+	dec := positional_line.NewDecoder(strings.NewReader(out.String()))
+	dec.UseWidthUnit(positional_line.WidthBytes)
+
+	var output []widthTestStruct
+
+	assert.Nil(t, dec.Decode(&output))
+	assert.Equal(t, []widthTestStruct{{"João", "São Jos"}, {"Concei", "Itajaí"}}, output)
+}
+
+func TestWidthBytesSplitCharacter(t *testing.T) {
+	dec := positional_line.NewDecoder(strings.NewReader("Jãããabcdefg"))
+	dec.UseWidthUnit(positional_line.WidthBytes)
+
+	var output widthTestStruct
+
+	err := dec.Decode(&output)
+
+	assert.ErrorIs(t, err, positional_line.ErrSplitCharacter)
+}
+
+func TestWidthBytesOverflow(t *testing.T) {
+	type TestStruct struct {
+		Name string `positional:"5,overflow=truncate-left"`
+		Code string `positional:"3,overflow=error"`
+	}
+
+	var out strings.Builder
+
+	enc := positional_line.NewEncoder(&out)
+	enc.SetWidthUnit(positional_line.WidthBytes)
+
+	assert.Nil(t, enc.Encode(TestStruct{"Conceição", "ab"}))
+	assert.Equal(t, "ção"+"ab ", out.String())
+
+	assert.ErrorIs(t, enc.Encode(TestStruct{"", "çãb"}), positional_line.ErrOverflow)
+}